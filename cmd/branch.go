@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// whether the commit already mentions the ticket anywhere
+func mentionsTicket(cc *parser.CC, ticket string) bool {
+	if strings.Contains(cc.Description, ticket) || strings.Contains(cc.Body, ticket) {
+		return true
+	}
+	for _, footer := range cc.Footers {
+		if strings.Contains(footer, ticket) {
+			return true
+		}
+	}
+	return false
+}
+
+// fill in any type or scope the current branch's name implies and the commit
+// doesn't already specify. Returns any ticket the branch name mentions.
+func prefillFromBranch(cc *parser.CC, cfg *config.Cfg, branch string) (ticket string) {
+	match, ok := cfg.MatchBranch(branch)
+	if !ok {
+		return ""
+	}
+	if cc.Type == "" && match.Type != "" {
+		if commitType := cfg.CanonicalType(match.Type); commitType != "" {
+			if _, valid := cfg.CommitTypes.Get(commitType); valid {
+				cc.Type = commitType
+			}
+		}
+	}
+	if cc.Scope == "" && match.Scope != "" {
+		if scope := cfg.CanonicalScope(match.Scope); scope != "" {
+			if _, valid := cfg.Scopes.Get(scope); valid {
+				cc.Scope = scope
+			}
+		}
+	}
+	return match.Ticket
+}
+
+// reference the ticket in the description or a footer, as configured. This
+// should be called once the description is final.
+func addTicket(cc *parser.CC, cfg *config.Cfg, ticket string) {
+	if ticket == "" || mentionsTicket(cc, ticket) {
+		return
+	}
+	if cfg.TicketHeaderFormat != "" {
+		cc.Description = cfg.TicketHeader(ticket) + cc.Description
+	} else if cfg.TicketFooter != "" {
+		cc.Footers = append(cc.Footers, cfg.TicketFooter+": "+ticket)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+const branchConfig = `
+scopes: [api, cli]
+branch_pattern: '^(?P<type>\w+)/(?:(?P<scope>[a-z]+)/)?(?P<ticket>[A-Z]+-\d+)'
+`
+
+func TestPrefillFromBranch(t *testing.T) {
+	_, cfg := fakeRepo(t, branchConfig)
+	for _, c := range []struct {
+		branch   string
+		given    parser.CC
+		expected parser.CC
+		ticket   string
+	}{
+		{"feat/api/PAY-1-refunds", parser.CC{}, parser.CC{Type: "feat", Scope: "api"}, "PAY-1"},
+		{"feat/api/PAY-1", parser.CC{Type: "fix", Scope: "cli"}, parser.CC{Type: "fix", Scope: "cli"}, "PAY-1"},
+		{"feature/web/PAY-1", parser.CC{}, parser.CC{}, "PAY-1"},
+		{"main", parser.CC{}, parser.CC{}, ""},
+	} {
+		cc := c.given
+		ticket := prefillFromBranch(&cc, cfg, c.branch)
+		if cc.Type != c.expected.Type || cc.Scope != c.expected.Scope || ticket != c.ticket {
+			fmt.Printf("%s: expected %s(%s) and %q, got %s(%s) and %q\n",
+				c.branch, c.expected.Type, c.expected.Scope, c.ticket, cc.Type, cc.Scope, ticket)
+			t.Fail()
+		}
+	}
+}
+
+func TestAddTicket(t *testing.T) {
+	test := func(config string, message string, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			_, cfg := fakeRepo(t, config)
+			cc, _ := parser.ParseAsMuchOfCCAsPossible(message)
+			addTicket(cc, cfg, "PAY-1")
+			if actual := strings.TrimSpace(cc.ToString()); actual != expected {
+				fmt.Printf("expected %q, got %q\n", expected, actual)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("a footer", test("", "feat: add x", "feat: add x\n\nRefs: PAY-1"))
+	t.Run("a custom footer", test("ticket_footer: Jira", "feat: add x", "feat: add x\n\nJira: PAY-1"))
+	t.Run("the header", test(`ticket_header_format: "[%s] "`, "feat: add x", "feat: [PAY-1] add x"))
+	t.Run("already mentioned", test("", "feat: add x\n\nfor PAY-1", "feat: add x\n\nfor PAY-1"))
+	t.Run("no ticket reference", test(`ticket_footer: ""`, "feat: add x", "feat: add x"))
+}
+
+func TestTicketHeaderLength(t *testing.T) {
+	_, cfg := fakeRepo(t, `ticket_header_format: "[%s] "
+header_max_length: 24
+enforce_header_max_length: true`)
+	// the ticket counts towards the header's length, leaving 10 characters
+	m := initialModel(&parser.CC{Type: "feat"}, cfg).withTicket("PAY-1")
+	m = typeKeys(m, "enter", "add fourteen x", "enter", "enter")
+	if expected := "feat: [PAY-1] add fourte"; strings.TrimSpace(m.value()) != expected {
+		fmt.Printf("expected %q, got %q\n", expected, m.value())
+		t.Fail()
+	}
+	if !m.ready() {
+		fmt.Printf("expected %q to fit, got %v\n", m.value(), m.violations)
+		t.Fail()
+	}
+	m = initialModel(&parser.CC{Type: "feat", Description: "PAY-1: add x"}, cfg).withTicket("PAY-1")
+	if strings.TrimSpace(m.value()) != "feat: PAY-1: add x" {
+		fmt.Printf("expected a mentioned ticket not to be repeated, got %q\n", m.value())
+		t.Fail()
+	}
+}
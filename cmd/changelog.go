@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/changelog"
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+)
+
+// matches remote urls like `git@github.com:o/r.git`,
+// `ssh://git@github.com:22/o/r.git`, or `https://user@github.com/o/r`
+var remotePattern = regexp.MustCompile(`^(?:[a-z+]+://)?(?:[^@/]+@)?([^:/]+)(?::\d+)?[:/](.+?)(?:\.git)?/?$`)
+
+// the web page of the `origin` remote, e.g. https://github.com/o/r, or "" if
+// there's no such remote
+func remoteWebURL(repo git.Repo) string {
+	url, err := repo.RemoteURL("origin")
+	if err != nil {
+		return ""
+	}
+	match := remotePattern.FindStringSubmatch(url)
+	if match == nil {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s", match[1], match[2])
+}
+
+// the configured link templates, with any unset ones guessed from the
+// `origin` remote
+func changelogLinks(cfg *config.Cfg) changelog.Links {
+	links := changelog.Links{
+		Commit:  cfg.CommitURLFormat,
+		Issue:   cfg.IssueURLFormat,
+		Compare: cfg.CompareURLFormat,
+	}
+	base := remoteWebURL(cfg.Repo)
+	if base == "" {
+		return links
+	}
+	if links.Commit == "" {
+		links.Commit = base + "/commit/{{hash}}"
+	}
+	if links.Issue == "" {
+		links.Issue = base + "/issues/{{id}}"
+		// tickets like PAY-123 probably live in another tracker
+		links.NumberedIssuesOnly = true
+	}
+	if links.Compare == "" {
+		links.Compare = base + "/compare/{{previousTag}}...{{currentTag}}"
+	}
+	return links
+}
+
+// collect the commits of the release ending at `to`, and of every earlier
+// release if `all` is set. Releases are tags matching `match`; the newest
+// release is named `name` unless `to` is tagged.
+func collectReleases(cfg *config.Cfg, pkg *monorepoPackage, to string, from string, name string, match string, all bool) ([]changelog.Release, error) {
+	releases := []changelog.Release{}
+	ref := to
+	for {
+		tag := exactTag(cfg.Repo, ref, match)
+		release := changelog.Release{Name: name, Ref: name, Date: time.Now().Format("2006-01-02")}
+		if tag != "" && (name == "" || name == changelog.Unreleased) {
+			release.Name, release.Ref, release.Date = tag, tag, commitDate(cfg.Repo, tag)
+		} else if name == "" || name == changelog.Unreleased {
+			release.Name, release.Ref = changelog.Unreleased, ref
+		}
+		release.Previous = from
+		if release.Previous == "" {
+			release.Previous = latestTag(cfg.Repo, ref, match, tag)
+		}
+		revRange := ref
+		if release.Previous != "" {
+			revRange = release.Previous + ".." + ref
+		}
+		commits, err := pkg.commits(cfg, revRange)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range commits {
+			release.Commits = append(release.Commits, changelog.Commit{Hash: c.SHA, Message: c.Message, Author: c.Author, Email: c.Email})
+		}
+		if !all || release.Name != changelog.Unreleased || len(commits) > 0 {
+			releases = append(releases, release)
+		}
+		if !all || release.Previous == "" {
+			return releases, nil
+		}
+		ref, name, from = release.Previous, "", ""
+	}
+}
+
+func runChangelog(cmd *cobra.Command, args []string) {
+	flags := cmd.Flags()
+	name, _ := flags.GetString("release")
+	from, _ := flags.GetString("from")
+	to, _ := flags.GetString("to")
+	match, _ := flags.GetString("match")
+	all, _ := flags.GetBool("all")
+	prepend, _ := flags.GetString("prepend")
+	pkg := packageFromFlags(cmd)
+	if pkg != nil && !flags.Changed("match") {
+		match = pkg.tagPrefix() + "*"
+	}
+	cfg, err := config.Init(true)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	releases, err := collectReleases(cfg, pkg, to, from, name, match, all)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	links := changelogLinks(cfg)
+	sections := make([]string, len(releases))
+	for i, release := range releases {
+		sections[i] = changelog.Render(cfg, links, release)
+	}
+	if prepend == "" {
+		fmt.Print(strings.Join(sections, "\n"))
+		return
+	}
+	existing, err := os.ReadFile(prepend)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatalf("unable to read %s: %+v", prepend, err)
+	}
+	updated, err := changelog.Prepend(string(existing), sections[0], releases[0].Name)
+	if err != nil {
+		log.Fatalf("%s: %s", prepend, err)
+	}
+	if err := os.WriteFile(prepend, []byte(updated), 0o644); err != nil {
+		log.Fatalf("unable to write to file %s: %+v", prepend, err)
+	}
+	fmt.Printf("added %s to %s\n", releases[0].Name, prepend)
+}
+
+func changelogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "generate a changelog from conventional commits",
+		Long: `Print a markdown changelog of the commits since the latest tag, grouped into
+sections by commit type, with breaking changes first. Each commit type's
+section is set by its "changelog" detail; hidden types are left out.`,
+		Example: `  git cc changelog --release v1.3.0 --prepend CHANGELOG.md
+  git cc changelog --all > CHANGELOG.md
+  git cc changelog --package pkg/parser --prepend pkg/parser/CHANGELOG.md`,
+		Args: cobra.NoArgs,
+		Run:  runChangelog,
+	}
+	flags := cmd.Flags()
+	flags.String("release", "", "the name of the new release, e.g. v1.3.0; defaults to the tag of --to, or "+changelog.Unreleased)
+	flags.String("from", "", "the previous release; defaults to the latest tag before --to")
+	flags.String("to", "HEAD", "the last commit of the release")
+	flags.String("match", "*", "a glob of the tags that mark releases, e.g. 'v*'; defaults to DIR/v* with --package DIR")
+	flags.Bool("all", false, "include every release, walking back through the tags")
+	flags.String("prepend", "", "add the release to the top of a changelog file, e.g. CHANGELOG.md, rather than printing it")
+	addPackageFlags(flags)
+	cmd.MarkFlagsMutuallyExclusive("all", "prepend")
+	cmd.MarkFlagsMutuallyExclusive("all", "from")
+	return cmd
+}
@@ -1,17 +1,16 @@
 package cmd
 
 import (
-	"bytes"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/lint"
 	"github.com/skalt/git-cc/internal/utils"
 	"github.com/skalt/git-cc/pkg/parser"
 )
@@ -32,11 +31,19 @@ func printVersion(version, commit, date string) {
 
 // construct a shell `git commit` command with flags delegated from the git-cc
 // cli
-func getGitCommitCmd(cmd *cobra.Command) []string {
+func getGitCommitCmd(cmd *cobra.Command, cfg *config.Cfg) []string {
 	commitCmd := []string{}
 	noEdit, _ := cmd.Flags().GetBool("no-edit")
 	message, _ := cmd.Flags().GetStringArray("message")
 	flags := cmd.Flags()
+	// a plain fixup's message is generated, so there's nothing to edit
+	if fixup, _ := flags.GetString("fixup"); fixup != "" && !strings.Contains(fixup, ":") {
+		noEdit = true
+	}
+	// like `git commit -C`, reusing a message means not editing it
+	if reuse, _ := flags.GetString("reuse-message"); reuse != "" {
+		noEdit = true
+	}
 	for _, name := range boolFlags {
 		if flags.Lookup(name).Changed {
 			flag, err := flags.GetBool(name)
@@ -45,7 +52,12 @@ func getGitCommitCmd(cmd *cobra.Command) []string {
 			}
 		}
 	}
-	if noEdit || len(message) > 0 {
+	for _, name := range stringFlags {
+		if value, _ := flags.GetString(name); value != "" {
+			commitCmd = append(commitCmd, "--"+name+"="+value)
+		}
+	}
+	if noEdit || len(message) > 0 || !cfg.Edit {
 		commitCmd = append(commitCmd, "--no-edit")
 	} else {
 		commitCmd = append(commitCmd, "--edit")
@@ -54,7 +66,7 @@ func getGitCommitCmd(cmd *cobra.Command) []string {
 }
 
 // run a potentially interactive `git commit`
-func doCommit(message string, dryRun bool, commitParams []string) {
+func doCommit(cfg *config.Cfg, message string, commitParams []string) {
 	f := config.GetCommitMessageFile()
 	file, err := os.Create(f)
 	if err != nil {
@@ -64,24 +76,12 @@ func doCommit(message string, dryRun bool, commitParams []string) {
 	if err != nil {
 		log.Fatalf("unable to write to %s: %+v", f, err)
 	}
-	if dryRun {
-		fmt.Println(message)
-	}
 	cmd := append([]string{"git", "commit", "--message", message}, commitParams...)
-	process := exec.Command(cmd[0], cmd[1:]...)
-	process.Stdin = os.Stdin
-	process.Stdout = os.Stdout
-	process.Stderr = os.Stderr
-	if dryRun {
+	if cfg.DryRun {
+		fmt.Println(message)
 		fmt.Printf("would run: `%s`\n", strings.Join(cmd, " "))
-		os.Exit(0)
-	} else {
-		err = process.Run()
-		if err != nil {
-			log.Fatalf("failed running `%+v`: %+v", cmd, err)
-		} else {
-			os.Exit(0)
-		}
+	} else if err := cfg.Repo.Commit(message, commitParams...); err != nil {
+		log.Fatalf("failed running `%+v`: %+v", cmd, err)
 	}
 }
 
@@ -89,6 +89,7 @@ func doCommit(message string, dryRun bool, commitParams []string) {
 // 0000 0010 : missing type
 // 0000 0100 : invalid scope
 // 0000 1000 : missing description
+// 0001 0000 : any other rule violation
 type ValidationErrors = uint8
 
 const (
@@ -96,67 +97,169 @@ const (
 	MissingType        uint8 = 1 << 1
 	InvalidScope       uint8 = 1 << 2
 	MissingDescription uint8 = 1 << 3
+	RuleViolation      uint8 = 1 << 4
 )
 
-// run the conventional-commit helper logic. This may/not break into the TUI.
-func mainMode(cmd *cobra.Command, args []string, cfg *config.Cfg) {
+// summarize error-level rule violations as ValidationErrors
+func toValidationErrors(violations []lint.Violation) (result ValidationErrors) {
+	for _, v := range violations {
+		if v.Level < config.RuleError {
+			continue
+		}
+		switch v.Rule {
+		case "type-empty":
+			result |= MissingType
+		case "type-enum", "type-case":
+			result |= InvalidType
+		case "scope-enum", "scope-case", "scope-empty":
+			result |= InvalidScope
+		case "subject-empty":
+			result |= MissingDescription
+		default:
+			result |= RuleViolation
+		}
+	}
+	return result
+}
+
+// whether the TUI can correct a violation; bodies and footers are only editable
+// through GIT_EDITOR.
+func fixableInTUI(v lint.Violation) bool {
+	switch v.Target() {
+	case "type", "scope", "subject", "header":
+		return true
+	default:
+		return false
+	}
+}
+
+func printViolations(violations []lint.Violation) {
+	for _, v := range violations {
+		fmt.Fprintln(os.Stderr, v.String())
+	}
+}
+
+// complete a commit through the TUI, then reference any ticket from the branch
+// name. Returns false if the user quit without submitting.
+func promptForMessage(m model, ticket string, options ...tea.ProgramOption) (string, bool) {
+	cfg := m.cfg
+	ui := tea.NewProgram(m.withTicket(ticket), options...)
+	out, err := ui.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	result := out.(model)
+	if !result.ready() {
+		return "", false
+	}
+	commitMessage := result.value()
+	if ticket != "" {
+		cc, _ := parser.ParseAsMuchOfCCAsPossible(commitMessage)
+		addTicket(cc, cfg, ticket)
+		commitMessage = cc.ToString()
+	}
+	return commitMessage, true
+}
+
+// parse an existing commit message for revision. A header that isn't a
+// conventional commit's becomes the description, leaving the type to be chosen.
+func parseRevision(message string) *parser.CC {
+	cc, err := parser.ParseAsMuchOfCCAsPossible(message)
+	if err == nil {
+		return cc
+	}
+	cc, _ = parser.ParseAsMuchOfCCAsPossible("_: " + message)
+	cc.Type = ""
+	return cc
+}
 
-	commitParams := getGitCommitCmd(cmd)
+// exit unless there's something to commit
+func requireStagedChanges(cmd *cobra.Command, cfg *config.Cfg) {
 	committingAllChanges, _ := cmd.Flags().GetBool("all")
 	allowEmpty, _ := cmd.Flags().GetBool("allow-empty")
 	if !cfg.DryRun && !committingAllChanges {
-		buf := &bytes.Buffer{}
-		process := exec.Command("git", "diff", "--name-only", "--cached")
-		process.Stdout = buf
-		err := process.Run()
+		staged, err := cfg.Repo.StagedFiles()
 		if err != nil {
 			log.Fatalf("fatal: not a git repository (or any of the parent directories): .git; %+v", err)
 		}
-		if buf.String() == "" && !allowEmpty {
+		if len(staged) == 0 && !allowEmpty {
 			log.Fatal("No files staged")
 		}
 	}
+}
+
+// run the conventional-commit helper logic. This may/not break into the TUI.
+func mainMode(cmd *cobra.Command, args []string, cfg *config.Cfg) {
+
+	commitParams := getGitCommitCmd(cmd, cfg)
+	// amending without staged changes only rewords the commit
+	amend, _ := cmd.Flags().GetBool("amend")
+	if !amend {
+		requireStagedChanges(cmd, cfg)
+	}
 
 	var cc *parser.CC
 
 	message, _ := cmd.Flags().GetStringArray("message")
-
+	var fullMessage string
 	if len(message) > 0 {
 		//> If multiple `-m` options are given, their values are concatenated as separate paragraphs.
 		//> see https://git-scm.com/docs/git-commit#Documentation/git-commit.txt---messageltmsggt
-		cc, _ = parser.ParseAsMuchOfCCAsPossible(strings.Join(message, "\n\n"))
+		fullMessage = strings.Join(message, "\n\n")
 	} else {
-		cc, _ = parser.ParseAsMuchOfCCAsPossible((strings.Join(args, " ")))
+		fullMessage = strings.Join(args, " ")
 	}
-	var validationErrors ValidationErrors = 0
-	if cc.Type == "" {
-		validationErrors |= InvalidType
-	} else {
-		if _, valid := cfg.CommitTypes.Get(cc.Type); !valid {
-			validationErrors |= InvalidType
+	// with no new message, `--amend` revises the message of HEAD
+	revisingHead := amend && strings.TrimSpace(fullMessage) == ""
+	if revisingHead {
+		head, err := cfg.Repo.HeadMessage()
+		if err != nil {
+			log.Fatalf("%s", err)
 		}
+		fullMessage = head
+		cc = parseRevision(fullMessage)
+	} else {
+		cc, _ = parser.ParseAsMuchOfCCAsPossible(fullMessage)
 	}
-	if cc.Scope != "" {
-		if _, valid := cfg.Scopes.Get(cc.Scope); !valid {
-			validationErrors |= InvalidScope
-		}
+	cc.Type = cfg.CanonicalType(cc.Type)
+	cc.Scope = cfg.CanonicalScope(cc.Scope)
+	var ticket string
+	if branch, err := cfg.Repo.Branch(); err == nil {
+		ticket = prefillFromBranch(cc, cfg, branch)
 	}
-	if cc.Description == "" {
-		validationErrors |= MissingDescription
+	if cfg.TicketHeaderFormat == "" {
+		// a ticket footer doesn't depend on the description, and may be required
+		addTicket(cc, cfg, ticket)
 	}
-
-	if validationErrors != 0 {
+	lintedCC, lintedMessage := cc, fullMessage
+	if cfg.TicketHeaderFormat != "" && ticket != "" {
+		// check the header as it'll be once it references the ticket
+		withTicket := *cc
+		addTicket(&withTicket, cfg, ticket)
+		lintedCC, lintedMessage = &withTicket, withTicket.ToString()
+	}
+	violations := lint.Lint(cfg, lintedCC, lintedMessage)
+	needsTUI := false
+	for _, v := range violations {
+		if v.Level < config.RuleError {
+			continue
+		}
+		// messages passed with -m are final: missing footers are reported
+		// rather than prompted for.
+		if fixableInTUI(v) || (v.Rule == "footer-required" && len(message) == 0) {
+			needsTUI = true
+			break
+		}
+	}
+	if needsTUI || revisingHead {
 		m := initialModel(cc, cfg)
-		ui := tea.NewProgram(m)
-		out, err := ui.Run()
-		if err != nil {
-			log.Fatal(err)
+		if revisingHead {
+			m = revisionModel(cc, cfg)
 		}
-		result := out.(model)
-		if !result.ready() {
+		commitMessage, ok := promptForMessage(m, ticket)
+		if !ok {
 			os.Exit(1) // no submission
 		} else {
-			commitMessage := result.value()
 			f := config.GetCommitMessageFile()
 			file, err := os.Create(f)
 			if err != nil {
@@ -166,10 +269,15 @@ func mainMode(cmd *cobra.Command, args []string, cfg *config.Cfg) {
 			if err != nil {
 				log.Fatalf("unable to write to file %s: %+v", f, err)
 			}
-			doCommit(commitMessage, cfg.DryRun, commitParams)
+			doCommit(cfg, commitMessage, commitParams)
 		}
 	} else {
-		doCommit(cc.ToString(), cfg.DryRun, commitParams)
+		printViolations(violations)
+		if lint.HasErrors(violations) {
+			os.Exit(int(toValidationErrors(violations)))
+		}
+		addTicket(cc, cfg, ticket)
+		doCommit(cfg, cc.ToString(), commitParams)
 	}
 }
 
@@ -203,9 +311,9 @@ func redoMessage(cmd *cobra.Command) {
 	utils.Check(flags.Set("message", string(data)))
 }
 
-// Note: I'm not using cobra subcommands since they prevent passing arbitrary arguments,
-// and I'd like to be able to start an invocation like `git-cc this is the commit message`
-// without having to think about whether `this` is a subcommand.
+// Note: the root command accepts arbitrary arguments so an invocation like
+// `git-cc this is the commit message` works. Subcommands are only matched by
+// their exact names, so keep them few and unlikely to start a commit message.
 
 func run(cmd *cobra.Command, args []string) {
 	flags := cmd.Flags()
@@ -224,17 +332,15 @@ func run(cmd *cobra.Command, args []string) {
 		if err != nil {
 			log.Fatalf("%s", err)
 		}
-		if showConfig, _ := flags.GetBool("show-config"); showConfig {
-			repoRoot, _ := config.GetGitRepoRoot()
-			_, tried, _ := config.FindCCConfigFile(repoRoot)
+		if err := lint.Validate(cfg.Rules); err != nil {
+			log.Fatalf("%s: %s", cfg.ConfigFile, err)
+		}
+		if show, _ := flags.GetBool("show-config"); show {
+			_, tried, _ := config.FindCCConfigFile(cfg.RepoRoot())
 			for _, f := range tried {
 				fmt.Printf("# %s\n", f)
 			}
-			file := cfg.ConfigFile
-			if file == "" {
-				file = "<default>"
-			}
-			fmt.Printf("config file path: %s\n", file)
+			showConfig(cfg, "yaml")
 			os.Exit(0)
 		}
 		if init := utils.Must(flags.GetBool("init")); init {
@@ -256,6 +362,14 @@ func run(cmd *cobra.Command, args []string) {
 		if redo := utils.Must(flags.GetBool("redo")); redo {
 			redoMessage(cmd)
 		}
+		if flags.Changed("reuse-message") || flags.Changed("reedit-message") {
+			reuseMode(cmd, args, cfg)
+			return
+		}
+		if flags.Changed("fixup") || flags.Changed("squash") {
+			fixupMode(cmd, args, cfg)
+			return
+		}
 		mainMode(cmd, args, cfg)
 	}
 }
@@ -295,6 +409,7 @@ func Cmd(version_, commit_, date_ string) (cmd *cobra.Command) {
 	cmd = &cobra.Command{
 		Use:   "git-cc",
 		Short: "write conventional commits",
+		Args:  cobra.ArbitraryArgs,
 		Run:   run,
 	}
 	{ // flags for git-cc
@@ -304,13 +419,17 @@ func Cmd(version_, commit_, date_ string) (cmd *cobra.Command) {
 		flags.Bool("redo", false, "Reuse your last commit message")
 		flags.StringArrayP("message", "m", []string{}, "pass a complete conventional commit. If valid, it'll be committed without editing.")
 		flags.Bool("version", false, "print the version")
-		flags.Bool("show-config", false, "print the path to the config file and the relevant config ")
+		flags.Bool("show-config", false, "print the config files searched for and the effective config; see `git cc config show`")
 		flags.Bool("allow-empty", false, "delegated to git-commit")
+		flags.Bool("amend", false, "replace the last commit; without a new message, revise its message in the TUI")
+		flags.String("fixup", "", "make a fixup! commit of `commit` for git rebase --autosquash, picking one if none is given; amend:<commit> and reword:<commit> replace its message")
+		flags.Lookup("fixup").NoOptDefVal = pickTarget
+		flags.String("squash", "", "make a squash! commit of `commit` for git rebase --autosquash, picking one if none is given")
+		flags.Lookup("squash").NoOptDefVal = pickTarget
+		flags.StringP("reuse-message", "C", "", "reuse the message and authorship of `commit`, revising the message in the TUI only if it's invalid")
+		flags.StringP("reedit-message", "c", "", "revise the message of `commit` in the TUI, reusing its authorship")
 		// TODO: accept more of git commit's flags; see https://git-scm.com/docs/git-commit
 		// likely: --cleanup=<mode>
-		// more difficult, and possibly better done manually: --amend, -C <commit>
-		// --reuse-message=<commit>, -c <commit>, --reedit-message=<commit>,
-		// --fixup=<commit>, --squash=<commit>
 		flags.String("author", "", "delegated to git-commit")
 		flags.String("date", "", "delegated to git-commit")
 		flags.BoolP("all", "a", false, "see the git-commit docs for --all|-a")
@@ -334,7 +453,18 @@ func Cmd(version_, commit_, date_ string) (cmd *cobra.Command) {
 
 		cmd.MarkFlagsMutuallyExclusive("signoff", "no-signoff")
 		cmd.MarkFlagsMutuallyExclusive("verify", "no-verify")
+		cmd.MarkFlagsMutuallyExclusive("fixup", "squash", "amend")
+		cmd.MarkFlagsMutuallyExclusive("fixup", "squash", "reuse-message", "reedit-message")
 	}
 	cmd.AddCommand(initCmd())
+	cmd.AddCommand(configCmd())
+	cmd.AddCommand(lintCmd())
+	cmd.AddCommand(hooksCmd())
+	cmd.AddCommand(prepareCommitMsgCmd())
+	cmd.AddCommand(changelogCmd())
+	cmd.AddCommand(versionCmd())
+	cmd.AddCommand(notesCmd())
+	cmd.AddCommand(rewordCmd())
+	cmd.AddCommand(convertCmd())
 	return cmd
 }
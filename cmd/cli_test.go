@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+)
+
+// a fake repo with some staged changes and, if given, a config file, along
+// with the config read from it
+func fakeRepo(t *testing.T, configFile string) (*git.Fake, *config.Cfg) {
+	for _, env := range [...]string{"GIT_DIR", "GIT_COMMON_DIR", "GIT_WORK_TREE", "XDG_CONFIG_DIRS"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	if configFile != "" {
+		if err := os.WriteFile(filepath.Join(root, "commit_convention.yaml"), []byte(configFile), 0o644); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+	}
+	fake := &git.Fake{
+		GitDirPath: t.TempDir(),
+		RootPath:   root,
+		BranchName: "main",
+		Staged:     []string{"README.md"},
+	}
+	cfg, err := config.InitIn(fake, false)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	return fake, cfg
+}
+
+func TestMainMode(t *testing.T) {
+	commit := func(cfg *config.Cfg, args ...string) {
+		cmd := Cmd("", "", "")
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		mainMode(cmd, cmd.Flags().Args(), cfg)
+	}
+	t.Run("commits a valid message without prompting", func(t *testing.T) {
+		fake, cfg := fakeRepo(t, "")
+		commit(cfg, "-m", "feat: add x")
+		if len(fake.Committed) != 1 {
+			fmt.Printf("expected one commit, got %+v\n", fake.Committed)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(fake.Committed[0].Message); actual != "feat: add x" {
+			fmt.Printf("unexpected message %q\n", actual)
+			t.Fail()
+		}
+		if !slices.Contains(fake.Committed[0].Args, "--no-edit") {
+			fmt.Printf("expected --no-edit with -m, got %q\n", fake.Committed[0].Args)
+			t.Fail()
+		}
+		data, _ := os.ReadFile(filepath.Join(fake.GitDirPath, "COMMIT_EDITMSG"))
+		if strings.TrimSpace(string(data)) != "feat: add x" {
+			fmt.Printf("expected the message in COMMIT_EDITMSG, got %q\n", data)
+			t.Fail()
+		}
+	})
+	t.Run("references the branch's ticket", func(t *testing.T) {
+		fake, cfg := fakeRepo(t, `branch_pattern: '^(?P<type>\w+)/(?P<ticket>[A-Z]+-\d+)'`)
+		fake.BranchName = "feat/PAY-123-refunds"
+		commit(cfg, "-m", "feat: add refunds", "--signoff")
+		if len(fake.Committed) != 1 {
+			fmt.Printf("expected one commit, got %+v\n", fake.Committed)
+			t.FailNow()
+		}
+		if !strings.HasSuffix(strings.TrimSpace(fake.Committed[0].Message), "Refs: PAY-123") {
+			fmt.Printf("expected a ticket footer, got %q\n", fake.Committed[0].Message)
+			t.Fail()
+		}
+		if !slices.Contains(fake.Committed[0].Args, "--signoff") {
+			fmt.Printf("expected --signoff to be passed on, got %q\n", fake.Committed[0].Args)
+			t.Fail()
+		}
+	})
+	t.Run("commits aliases as their canonical type and scope", func(t *testing.T) {
+		fake, cfg := fakeRepo(t, "commit_types: [{feat: {aliases: [feature]}}, fix]\nscopes: [{cmd: {aliases: [cli]}}]")
+		commit(cfg, "-m", "feature(cli): x")
+		if len(fake.Committed) != 1 {
+			fmt.Printf("expected one commit, got %+v\n", fake.Committed)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(fake.Committed[0].Message); actual != "feat(cmd): x" {
+			fmt.Printf("expected the canonical type and scope, got %q\n", actual)
+			t.Fail()
+		}
+	})
+	t.Run("rejects -m messages missing required footers", func(t *testing.T) {
+		// mainMode exits, so commit in a copy of this test
+		if os.Getenv("GIT_CC_TEST_MAIN_MODE") == "1" {
+			fake, cfg := fakeRepo(t, "required_footers: {fix: [{token: Refs}]}")
+			commit(cfg, "-m", "fix: a typo")
+			fmt.Printf("committed %+v\n", fake.Committed)
+			return
+		}
+		run := exec.Command(os.Args[0], "-test.run=^TestMainMode$/^rejects_-m_messages")
+		run.Env = append(os.Environ(), "GIT_CC_TEST_MAIN_MODE=1")
+		out, err := run.CombinedOutput()
+		var exit *exec.ExitError
+		if !errors.As(err, &exit) || exit.ExitCode() != int(RuleViolation) {
+			fmt.Printf("expected exit status %d, got %v:\n%s\n", RuleViolation, err, out)
+			t.FailNow()
+		}
+		if !strings.Contains(string(out), "fix commits must have footers: Refs") {
+			fmt.Printf("expected the missing footer to be reported, got:\n%s\n", out)
+			t.Fail()
+		}
+	})
+	t.Run("dry runs don't commit", func(t *testing.T) {
+		fake, cfg := fakeRepo(t, "")
+		cfg.DryRun = true
+		commit(cfg, "-m", "feat: add x")
+		if len(fake.Committed) != 0 {
+			fmt.Printf("expected no commits, got %+v\n", fake.Committed)
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/lint"
+	"github.com/skalt/git-cc/internal/utils"
+)
+
+// print the effective configuration annotated with where each value came from
+func showConfig(cfg *config.Cfg, format string) {
+	effective := cfg.Clone()
+	effective.Rules = lint.EffectiveRules(cfg)
+	out, err := config.RenderEntries(effective.Entries(), format)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	fmt.Print(out)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	cfg, err := config.Init(true) // no need for a git repo or staged changes
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	if err := lint.Validate(cfg.Rules); err != nil {
+		log.Fatalf("%s: %s", cfg.ConfigFile, err)
+	}
+	showConfig(cfg, format)
+}
+
+// translate the config file, or the file passed as an argument, into another
+// format alongside the original.
+func runConfigConvert(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("to")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	var configFile string
+	if len(args) > 0 {
+		configFile = args[0]
+	} else {
+		cfg, err := config.Init(true)
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+		if cfg.ConfigFile == "" {
+			log.Fatal("no config file found to convert")
+		}
+		configFile = cfg.ConfigFile
+	}
+	target, contents, err := config.ConvertCfgFile(configFile, format)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	if dryRun {
+		fmt.Print(contents)
+		return
+	}
+	if _, err := os.Stat(target); err == nil {
+		log.Fatalf("%s already exists", target)
+	}
+	if err := os.WriteFile(target, []byte(contents), 0o644); err != nil {
+		log.Fatalf("unable to write to file %s: %+v", target, err)
+	}
+	fmt.Printf("wrote %s\n", target)
+	fmt.Printf("hint: remove %s so git-cc reads %s instead\n", configFile, target)
+}
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "inspect or convert the configuration",
+	}
+	show := &cobra.Command{
+		Use:   "show",
+		Short: "print the effective configuration and where each value came from",
+		Args:  cobra.NoArgs,
+		Run:   runConfigShow,
+	}
+	show.Flags().String("format", "yaml", "one of: yaml, toml, json")
+	convert := &cobra.Command{
+		Use:   "convert [FILE]",
+		Short: "translate a config file to another format",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runConfigConvert,
+	}
+	convert.Flags().String("to", "", "one of: yaml, toml, json")
+	convert.Flags().Bool("dry-run", false, "print the converted file rather than writing it")
+	utils.Check(convert.MarkFlagRequired("to"))
+	cmd.AddCommand(show, convert)
+	return cmd
+}
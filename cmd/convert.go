@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/internal/i18n"
+	"github.com/skalt/git-cc/internal/lint"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// commit types implied by changing only certain kinds of files
+var pathTypes = [...]struct {
+	commitType string
+	matches    func(file string) bool
+}{
+	{"docs", func(file string) bool {
+		ext := path.Ext(file)
+		return ext == ".md" || ext == ".rst" || ext == ".adoc" || hasDir(file, "docs", "doc") ||
+			strings.HasPrefix(strings.ToUpper(path.Base(file)), "LICENSE")
+	}},
+	{"test", func(file string) bool {
+		base := path.Base(file)
+		return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") ||
+			strings.Contains(base, ".spec.") || hasDir(file, "test", "tests", "testdata", "__tests__")
+	}},
+	{"ci", func(file string) bool {
+		return strings.HasPrefix(file, ".github/workflows/") || strings.HasPrefix(file, ".circleci/") ||
+			slices.Contains([]string{".gitlab-ci.yml", ".travis.yml", "Jenkinsfile"}, file)
+	}},
+	{"build", func(file string) bool {
+		return slices.Contains([]string{
+			"go.mod", "go.sum", "Makefile", "Dockerfile",
+			"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+			"Cargo.toml", "Cargo.lock", "pyproject.toml", "requirements.txt",
+		}, path.Base(file))
+	}},
+}
+
+// commit types implied by the first word of a message like "Fixed the login"
+var verbTypes = map[string][]string{
+	"fix":      {"fix", "fixed", "fixes", "fixing", "bugfix", "hotfix", "resolve", "resolved", "resolves", "correct", "corrected"},
+	"feat":     {"add", "added", "adds", "adding", "implement", "implemented", "implements", "introduce", "introduced", "support", "allow", "new"},
+	"refactor": {"refactor", "refactored", "refactoring", "rename", "renamed", "move", "moved", "extract", "extracted", "simplify", "simplified", "cleanup", "clean"},
+	"perf":     {"optimize", "optimized", "speed", "perf"},
+	"docs":     {"doc", "docs", "document", "documented", "readme"},
+	"test":     {"test", "tests", "tested", "testing"},
+	"build":    {"bump", "bumped", "upgrade", "upgraded", "deps"},
+	"style":    {"format", "formatted", "reformat", "lint", "style"},
+	"revert":   {"revert", "reverted", "reverts"},
+}
+
+// whether any directory of a slash-separated path is one of `dirs`
+func hasDir(file string, dirs ...string) bool {
+	parts := strings.Split(file, "/")
+	for _, dir := range parts[:len(parts)-1] {
+		if slices.Contains(dirs, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// the type a commit's files or first word suggest that's configured, or ""
+func suggestType(cfg *config.Cfg, description string, files []string) string {
+	configured := func(commitType string) bool {
+		_, ok := cfg.CommitTypes.Get(commitType)
+		return ok
+	}
+	if len(files) > 0 {
+		for _, kind := range pathTypes {
+			if configured(kind.commitType) && !slices.ContainsFunc(files, func(f string) bool { return !kind.matches(f) }) {
+				return kind.commitType
+			}
+		}
+	}
+	word, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(description)), " ")
+	word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) })
+	for commitType, verbs := range verbTypes {
+		if slices.Contains(verbs, word) && configured(commitType) {
+			return commitType
+		}
+	}
+	if commitType := cfg.CanonicalType(word); configured(commitType) {
+		return commitType // e.g. an alias like "bug"
+	}
+	return ""
+}
+
+// the one configured scope, if any, that each of a commit's files is under,
+// e.g. "parser" for pkg/parser/parser.go
+func suggestScope(cfg *config.Cfg, files []string) string {
+	suggestion := ""
+	for _, file := range files {
+		found := ""
+		for _, part := range strings.Split(file, "/") {
+			part = strings.TrimSuffix(part, path.Ext(part))
+			scope := cfg.CanonicalScope(part)
+			if _, ok := cfg.ActiveScopes().Get(scope); ok {
+				found = scope
+				break
+			}
+		}
+		if found == "" || (suggestion != "" && found != suggestion) {
+			return ""
+		}
+		suggestion = found
+	}
+	return suggestion
+}
+
+// a conventional commit to start converting a message from, keeping its
+// description, body, and footers
+func suggestConversion(cfg *config.Cfg, message string, files []string) *parser.CC {
+	cc := parseRevision(message)
+	cc.Type, cc.Scope = cfg.CanonicalType(cc.Type), cfg.CanonicalScope(cc.Scope)
+	if _, valid := cfg.CommitTypes.Get(cc.Type); !valid && cc.Type != "" {
+		// a header like `parser: handle tabs` names a scope rather than a type
+		if _, isScope := cfg.Scopes.Get(cfg.CanonicalScope(cc.Type)); isScope && cc.Scope == "" {
+			cc.Scope = cfg.CanonicalScope(cc.Type)
+		}
+		cc.Type = ""
+	}
+	if cc.Type == "" {
+		cc.Type = suggestType(cfg, cc.Description, files)
+	}
+	if _, valid := cfg.Scopes.Get(cc.Scope); !valid {
+		cc.Scope = suggestScope(cfg, files)
+	}
+	return cc
+}
+
+// the start of a range like `origin/main..HEAD` to convert. The range must end
+// at HEAD, and a single revision like `origin/main` means `origin/main..HEAD`.
+func convertFrom(repo git.Repo, revRange string) (string, error) {
+	from, to, _ := strings.Cut(revRange, "..")
+	if from == "" {
+		return "", fmt.Errorf("%s has no start; convert needs a range like origin/main..HEAD", revRange)
+	}
+	if _, err := repo.ResolveRef(from); err != nil {
+		return "", err
+	}
+	if to != "" {
+		end, err := repo.ResolveRef(to)
+		if err != nil {
+			return "", err
+		}
+		if head, _ := repo.ResolveRef("HEAD"); end != head {
+			return "", fmt.Errorf("%s isn't HEAD; convert only rewrites the current branch", to)
+		}
+	}
+	return from, nil
+}
+
+// the first line of a suggested conversion, or a note that there's none
+func suggestedHeader(cc *parser.CC) string {
+	if cc.Type == "" {
+		return "(no type suggested)"
+	}
+	header, _, _ := strings.Cut(cc.ToString(), "\n")
+	return header
+}
+
+func runConvert(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	cfg, err := config.Init(dryRun)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	from, err := convertFrom(cfg.Repo, args[0])
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	commits, err := readCommits(cfg.Repo, from+"..HEAD")
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	pending := []git.Commit{}
+	first := -1
+	for i, c := range commits {
+		if skipReason(c.Message, len(c.Parents), skippable[:]) != "" {
+			continue
+		}
+		if lint.HasErrors(lintMessage(cfg, c.Message)) {
+			pending = append(pending, c)
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if len(pending) == 0 {
+		fmt.Printf("every commit in %s..HEAD is already conventional\n", from)
+		return
+	}
+	if err := checkRewritable(cfg, commits[first:]); err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	suggestions := make([]*parser.CC, len(pending))
+	for i, c := range pending {
+		files, err := cfg.Repo.ChangedFiles(c.SHA)
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+		suggestions[i] = suggestConversion(cfg, c.Message, files)
+	}
+	if dryRun {
+		// print the suggestions as-is, rather than prompting for each commit
+		for i, c := range pending {
+			fmt.Printf("%s %s → %s\n", shortSHA(c.SHA), c.Header(), suggestedHeader(suggestions[i]))
+		}
+		return
+	}
+
+	messages := map[string]string{}
+	for i, c := range pending {
+		m := revisionModel(suggestions[i], cfg)
+		m.heading = config.Faint(fmt.Sprintf(i18n.T("prompt.convert"), i+1, len(pending), shortSHA(c.SHA)+" "+c.Header()))
+		message, ok := promptForMessage(m, "")
+		if !ok {
+			os.Exit(1) // nothing's rewritten unless every commit is submitted
+		}
+		if strings.TrimSpace(message) != strings.TrimSpace(c.Message) {
+			messages[c.SHA] = message
+		}
+	}
+	rewritten, err := rewriteHistory(cfg.Repo, commits[first:], messages, "git-cc convert "+args[0])
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	fmt.Printf("converted %d commits, rewriting %d\n", len(messages), len(rewritten))
+}
+
+func convertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert <range>",
+		Short: "convert a branch's earlier commits into conventional commits",
+		Long: `Revise each commit in a range like origin/main..HEAD that isn't a conventional
+commit in the TUI, starting from a type and scope suggested by the files it
+changes and the first word of its message. Once every commit is revised, the
+branch is rewritten in one pass. A single revision like origin/main means
+origin/main..HEAD.
+
+Like reword, convert only changes messages and refuses to rewrite commits that
+are part of a protected ref or that are followed by merges.`,
+		Example: `  git cc convert origin/main
+  git cc convert --dry-run HEAD~10..HEAD`,
+		Args: cobra.ExactArgs(1),
+		Run:  runConvert,
+	}
+	cmd.Flags().Bool("dry-run", false, "print the suggested header of each commit rather than prompting or rewriting anything")
+	return cmd
+}
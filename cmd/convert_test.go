@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+func TestSuggestType(t *testing.T) {
+	_, cfg := fakeRepo(t, "")
+	for _, c := range []struct {
+		description string
+		files       []string
+		expected    string
+	}{
+		{"Update the guide", []string{"README.md", "docs/setup.txt"}, "docs"},
+		{"Cover tabs", []string{"pkg/parser/parser_test.go", "pkg/parser/testdata/tabs.txt"}, "test"},
+		{"Run on macOS", []string{".github/workflows/ci.yml"}, "ci"},
+		{"Update cobra", []string{"go.mod", "go.sum"}, "build"},
+		{"Fixed the crash", []string{"main.go"}, "fix"},
+		{"Added: refunds", []string{"main.go", "README.md"}, "feat"},
+		{"refactoring the parser", nil, "refactor"},
+		{"Update things", []string{"main.go"}, ""},
+		{"", nil, ""},
+	} {
+		if actual := suggestType(cfg, c.description, c.files); actual != c.expected {
+			fmt.Printf("%q changing %v: expected %q, got %q\n", c.description, c.files, c.expected, actual)
+			t.Fail()
+		}
+	}
+	t.Run("only configured types", func(t *testing.T) {
+		_, cfg := fakeRepo(t, "commit_types: [feat, fix]")
+		if actual := suggestType(cfg, "Document x", []string{"README.md"}); actual != "" {
+			fmt.Printf("expected no suggestion, got %q\n", actual)
+			t.Fail()
+		}
+	})
+}
+
+func TestSuggestScope(t *testing.T) {
+	_, cfg := fakeRepo(t, "scopes: [parser, cli]")
+	for _, c := range []struct {
+		files    []string
+		expected string
+	}{
+		{[]string{"pkg/parser/parser.go", "pkg/parser/lexer.go"}, "parser"},
+		{[]string{"cmd/cli.go"}, "cli"},
+		{[]string{"pkg/parser/parser.go", "cmd/cli.go"}, ""},
+		{[]string{"pkg/parser/parser.go", "README.md"}, ""},
+		{nil, ""},
+	} {
+		if actual := suggestScope(cfg, c.files); actual != c.expected {
+			fmt.Printf("%v: expected %q, got %q\n", c.files, c.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestSuggestConversion(t *testing.T) {
+	_, cfg := fakeRepo(t, "scopes: [parser, cli]")
+	for _, c := range []struct {
+		message  string
+		files    []string
+		expected string
+	}{
+		{"Fixed the login\n\nbody", []string{"cmd/cli.go"}, "fix(cli): Fixed the login"},
+		{"parser: handle tabs", []string{"pkg/parser/parser.go"}, "(no type suggested)"},
+		{"parser: add tabs", []string{"cmd/cli.go"}, "feat(parser): add tabs"},
+		{"feature: add tabs", nil, "feat: add tabs"},
+		{"feat(web): add tabs", []string{"pkg/parser/parser.go"}, "feat(parser): add tabs"},
+	} {
+		cc := suggestConversion(cfg, c.message, c.files)
+		if actual := suggestedHeader(cc); actual != c.expected {
+			fmt.Printf("%q: expected %q, got %q\n", c.message, c.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestConvertFrom(t *testing.T) {
+	fake, _ := fakeRepo(t, "")
+	fake.History = []git.Commit{{SHA: "c"}, {SHA: "b"}, {SHA: "a"}}
+	fake.Refs = map[string]string{"refs/remotes/origin/main": "a"}
+	for _, c := range []struct {
+		revRange string
+		expected string
+		problem  string
+	}{
+		{"origin/main", "origin/main", ""},
+		{"origin/main..HEAD", "origin/main", ""},
+		{"origin/main..", "origin/main", ""},
+		{"a..c", "a", ""},
+		{"..HEAD", "", "has no start"},
+		{"a..b", "", "b isn't HEAD"},
+		{"a..nonexistent", "", "unknown revision"},
+		{"nonexistent", "", "unknown revision"},
+	} {
+		from, err := convertFrom(fake, c.revRange)
+		if from != c.expected || (err == nil) != (c.problem == "") || (err != nil && !strings.Contains(err.Error(), c.problem)) {
+			fmt.Printf("%s: expected %q and %q, got %q and %v\n", c.revRange, c.expected, c.problem, from, err)
+			t.Fail()
+		}
+	}
+}
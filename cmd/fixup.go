@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/commit_selector"
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/internal/i18n"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// the value of a bare --fixup or --squash: pick the commit interactively
+const pickTarget = "<pick>"
+
+// how many of the latest commits the picker offers
+const pickableCommits = 30
+
+// a TUI for picking a commit
+type commitPicker struct {
+	input  commit_selector.Model
+	picked bool
+}
+
+var _ tea.Model = commitPicker{}
+
+func (m commitPicker) Init() tea.Cmd {
+	return nil
+}
+
+func (m commitPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "ctrl+d":
+			return m, tea.Quit
+		case "enter", "tab":
+			if m.input.Value() == "" {
+				return m, nil
+			}
+			m.picked = true
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m commitPicker) View() (v tea.View) {
+	v.AltScreen = true
+	s := strings.Builder{}
+	m.input.Render(&s)
+	s.WriteString("\n")
+	v.Content = s.String()
+	return v
+}
+
+// pick one of the latest commits. Returns false if the user quit without
+// picking.
+func pickCommit(cfg *config.Cfg, prompt string) (git.Commit, bool) {
+	commits, err := recentCommits(cfg.Repo, pickableCommits)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	if len(commits) == 0 {
+		log.Fatal("no commits to pick from")
+	}
+	hashes, headers := make([]string, len(commits)), make([]string, len(commits))
+	for i, c := range commits {
+		hashes[i], headers[i] = c.SHA, c.Header()
+	}
+	ui := tea.NewProgram(commitPicker{input: commit_selector.NewModel(prompt, hashes, headers, cfg)})
+	out, err := ui.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	result := out.(commitPicker)
+	if !result.picked {
+		return git.Commit{}, false
+	}
+	for _, c := range commits {
+		if strings.HasPrefix(c.SHA, result.input.Value()) {
+			return c, true
+		}
+	}
+	return git.Commit{}, false
+}
+
+// split the value of --fixup, like `amend:HEAD~2`, into the kind of fixup
+// ("", "amend", or "reword") and its target
+func splitFixup(value string) (kind string, target string) {
+	for _, kind := range [...]string{"amend", "reword"} {
+		if target, ok := strings.CutPrefix(value, kind+":"); ok {
+			return kind, target
+		}
+	}
+	return "", value
+}
+
+// commit a fixup or squash commit for `git rebase --autosquash`, picking its
+// target if none was given. `amend!` and `squash!` commits get their new
+// message from the TUI.
+func fixupMode(cmd *cobra.Command, args []string, cfg *config.Cfg) {
+	flags := cmd.Flags()
+	if message, _ := flags.GetStringArray("message"); len(message) > 0 {
+		log.Fatal("-m|--message is incompatible with --fixup and --squash")
+	}
+	flag, kind, target := "squash", "squash", ""
+	if flags.Changed("fixup") {
+		value, _ := flags.GetString("fixup")
+		flag = "fixup"
+		kind, target = splitFixup(value)
+	} else {
+		target, _ = flags.GetString("squash")
+	}
+	if err := checkFixupArgs(cfg.Repo, flag, target, args); err != nil {
+		log.Fatalf("%s", err)
+	}
+	commitParams := getGitCommitCmd(cmd, cfg)
+	if kind == "reword" {
+		// like `git commit --fixup=reword:`, leave out any staged changes
+		commitParams = append(commitParams, "--only", "--allow-empty")
+	} else {
+		requireStagedChanges(cmd, cfg)
+	}
+
+	var commit git.Commit
+	if target == "" || target == pickTarget {
+		prompt := i18n.T("prompt.fixup")
+		if kind == "squash" {
+			prompt = i18n.T("prompt.squash")
+		}
+		var ok bool
+		if commit, ok = pickCommit(cfg, prompt); !ok {
+			os.Exit(1) // nothing picked
+		}
+	} else {
+		var err error
+		if commit, err = readCommit(cfg.Repo, target); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	var m model
+	switch kind {
+	case "":
+		doCommit(cfg, fixupMessage(kind, commit, ""), commitParams)
+		return
+	case "squash":
+		m = initialModel(&parser.CC{}, cfg)
+	default: // replace the target's message
+		m = revisionModel(parseRevision(commit.Message), cfg)
+	}
+	message, ok := promptForMessage(m, "")
+	if !ok {
+		os.Exit(1) // no submission
+	}
+	doCommit(cfg, fixupMessage(kind, commit, message), commitParams)
+}
+
+// git-cc takes no positional arguments alongside --fixup or --squash. Since a
+// bare --fixup picks its target, `--fixup abc123` would otherwise pick a
+// commit and drop abc123, so point out the missing `=`.
+func checkFixupArgs(repo git.Repo, flag string, target string, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if target == pickTarget {
+		if _, err := repo.ResolveRef(args[0] + "^{commit}"); err == nil {
+			return fmt.Errorf("--%s takes its commit after an =: try --%s=%s", flag, flag, args[0])
+		}
+	}
+	return fmt.Errorf("unexpected arguments %q; pass the commit like --%s=<commit>", args, flag)
+}
+
+// the message of a fixup commit of the given kind, which `git rebase
+// --autosquash` matches to its target by the target's header. `reword:`
+// fixups are `amend!` commits that don't change any files.
+func fixupMessage(kind string, target git.Commit, message string) string {
+	switch kind {
+	case "":
+		return "fixup! " + target.Header()
+	case "reword":
+		kind = "amend"
+	}
+	return kind + "! " + target.Header() + "\n\n" + message
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+func TestSplitFixup(t *testing.T) {
+	for _, c := range []struct {
+		value, kind, target string
+	}{
+		{"HEAD~2", "", "HEAD~2"},
+		{"amend:HEAD~2", "amend", "HEAD~2"},
+		{"reword:abc123", "reword", "abc123"},
+		{"amend:", "amend", ""},
+		{pickTarget, "", pickTarget},
+		{"squash:abc123", "", "squash:abc123"},
+	} {
+		kind, target := splitFixup(c.value)
+		if kind != c.kind || target != c.target {
+			fmt.Printf("%q: expected %q, %q; got %q, %q\n", c.value, c.kind, c.target, kind, target)
+			t.Fail()
+		}
+	}
+}
+
+func TestFixupMessage(t *testing.T) {
+	target := git.Commit{SHA: "abc123", Message: "feat: add x\n\nbody"}
+	for _, c := range []struct {
+		kind, message, expected string
+	}{
+		{"", "ignored", "fixup! feat: add x"},
+		{"squash", "feat: add y", "squash! feat: add x\n\nfeat: add y"},
+		{"amend", "feat: add y", "amend! feat: add x\n\nfeat: add y"},
+		{"reword", "feat: add y", "amend! feat: add x\n\nfeat: add y"},
+	} {
+		if actual := fixupMessage(c.kind, target, c.message); actual != c.expected {
+			fmt.Printf("%q: expected %q, got %q\n", c.kind, c.expected, actual)
+			t.Fail()
+		}
+	}
+	t.Run("rewording starts from the target's message", func(t *testing.T) {
+		_, cfg := fakeRepo(t, "")
+		m := revisionModel(parseRevision(target.Message), cfg)
+		if actual := strings.TrimSpace(m.value()); actual != "feat: add x\n\nbody" {
+			fmt.Printf("unexpected prefill %q\n", actual)
+			t.Fail()
+		}
+	})
+}
+
+func TestCheckFixupArgs(t *testing.T) {
+	fake, _ := fakeRepo(t, "")
+	fake.History = []git.Commit{{SHA: "abc1234", Message: "feat: add x"}}
+	for _, c := range []struct {
+		target  string
+		args    []string
+		problem string
+	}{
+		{pickTarget, nil, ""},
+		{"abc1234", nil, ""},
+		{pickTarget, []string{"abc1"}, "try --fixup=abc1"},
+		{pickTarget, []string{"HEAD"}, "try --fixup=HEAD"},
+		{pickTarget, []string{"feat: add y"}, "unexpected arguments"},
+		{"abc1234", []string{"HEAD"}, "unexpected arguments"},
+	} {
+		err := checkFixupArgs(fake, "fixup", c.target, c.args)
+		if (err == nil) != (c.problem == "") || (err != nil && !strings.Contains(err.Error(), c.problem)) {
+			fmt.Printf("%q %q: expected %q, got %v\n", c.target, c.args, c.problem, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestFixupMode(t *testing.T) {
+	fake, cfg := fakeRepo(t, "")
+	fake.History = []git.Commit{{SHA: "abc1234", Message: "feat: add x\n\nbody"}}
+	cmd := Cmd("", "", "")
+	if err := cmd.ParseFlags([]string{"--fixup=HEAD"}); err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	fixupMode(cmd, cmd.Flags().Args(), cfg)
+	if len(fake.Committed) != 1 {
+		fmt.Printf("expected one commit, got %+v\n", fake.Committed)
+		t.FailNow()
+	}
+	if actual := strings.TrimSpace(fake.Committed[0].Message); actual != "fixup! feat: add x" {
+		fmt.Printf("unexpected message %q\n", actual)
+		t.Fail()
+	}
+	if !slices.Contains(fake.Committed[0].Args, "--no-edit") {
+		fmt.Printf("expected --no-edit, got %q\n", fake.Committed[0].Args)
+		t.Fail()
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+// run `git log` with the given arguments, collecting the commits it lists
+func logCommits(repo git.Repo, args ...string) ([]git.Commit, error) {
+	commits := []git.Commit{}
+	for commit, err := range repo.Log(args...) {
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// read the commits in a revision range like `origin/main..HEAD`, oldest first.
+// Any paths limit the commits to those that change files under them.
+func readCommits(repo git.Repo, revRange string, paths ...string) ([]git.Commit, error) {
+	return logCommits(repo, append([]string{"--reverse", revRange, "--"}, paths...)...)
+}
+
+// read the commit a ref like `HEAD~2` points to
+func readCommit(repo git.Repo, ref string) (git.Commit, error) {
+	commits, err := logCommits(repo, "-1", ref, "--")
+	if err != nil {
+		return git.Commit{}, err
+	}
+	if len(commits) == 0 {
+		return git.Commit{}, fmt.Errorf("no commit %s", ref)
+	}
+	return commits[0], nil
+}
+
+// read the latest commits on HEAD, newest first
+func recentCommits(repo git.Repo, n int) ([]git.Commit, error) {
+	return logCommits(repo, "-n", strconv.Itoa(n), "HEAD", "--")
+}
+
+// the newest tag matching a glob that's reachable from a ref, other than
+// `exclude`, or "" if there's none
+func latestTag(repo git.Repo, ref string, match string, exclude string) string {
+	tag, err := repo.NearestTag(ref, match, exclude)
+	if err != nil {
+		return ""
+	}
+	return tag
+}
+
+// the tag matching a glob that points at a ref, or "" if there's none
+func exactTag(repo git.Repo, ref string, match string) string {
+	tag, err := repo.TagAt(ref, match)
+	if err != nil {
+		return ""
+	}
+	return tag
+}
+
+// the date a ref was authored on, as YYYY-MM-DD
+func commitDate(repo git.Repo, ref string) string {
+	commit, err := readCommit(repo, ref)
+	if err != nil || len(commit.Date) < len("2006-01-02") {
+		return ""
+	}
+	return commit.Date[:len("2006-01-02")]
+}
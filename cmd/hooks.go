@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+)
+
+// marks hook scripts that git-cc wrote, so they can be updated and removed
+const hookMarker = "# installed by git-cc"
+
+// the suffix of hooks that were present before git-cc's, which git-cc's hooks
+// run first
+const chainedHookSuffix = ".pre-git-cc"
+
+// the command each hook runs, given the hook's arguments as "$@"
+var hookCommands = map[string]string{
+	"commit-msg":         `git-cc lint --file "$1"`,
+	"prepare-commit-msg": `git-cc prepare-commit-msg "$@"`,
+}
+
+func hookScript(name string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s; remove with `+"`git cc hooks uninstall`"+`
+chained="$0%s"
+if [ -x "$chained" ]; then
+  "$chained" "$@" || exit $?
+fi
+exec %s
+`, hookMarker, chainedHookSuffix, hookCommands[name])
+}
+
+// whether the file at path is a hook git-cc wrote
+func isOwnHook(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), hookMarker)
+}
+
+func hooksDir() string {
+	cfg, err := config.Init(true)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	dir, err := config.HooksDir(cfg.Repo)
+	if err != nil {
+		log.Fatalf("unable to find the git hooks directory: %+v", err)
+	}
+	return dir
+}
+
+// write a hook, moving any existing hook aside to be chained to
+func installHook(dir string, name string) {
+	path := filepath.Join(dir, name)
+	chained := path + chainedHookSuffix
+	if _, err := os.Stat(path); err == nil && !isOwnHook(path) {
+		if _, err := os.Stat(chained); err == nil {
+			log.Fatalf("unable to install %s: both %s and %s exist", name, path, chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			log.Fatalf("unable to move %s aside: %+v", path, err)
+		}
+		fmt.Printf("moved %s to %s; it will run before git-cc's hook\n", path, chained)
+	}
+	if err := os.WriteFile(path, []byte(hookScript(name)), 0o755); err != nil {
+		log.Fatalf("unable to write %s: %+v", path, err)
+	}
+	fmt.Printf("installed %s\n", path)
+}
+
+// remove a hook git-cc wrote, restoring any hook it chained to
+func uninstallHook(dir string, name string) {
+	path := filepath.Join(dir, name)
+	chained := path + chainedHookSuffix
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	if !isOwnHook(path) {
+		fmt.Printf("left %s in place since git-cc didn't write it\n", path)
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		log.Fatalf("unable to remove %s: %+v", path, err)
+	}
+	fmt.Printf("removed %s\n", path)
+	if _, err := os.Stat(chained); err == nil {
+		if err := os.Rename(chained, path); err != nil {
+			log.Fatalf("unable to restore %s: %+v", chained, err)
+		}
+		fmt.Printf("restored %s\n", path)
+	}
+}
+
+// describe what's wrong with an installed hook, if anything
+func checkHook(dir string, name string) (problems []string) {
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return []string{fmt.Sprintf("%s is missing", path)}
+	}
+	if !isOwnHook(path) {
+		return []string{fmt.Sprintf("%s wasn't installed by git-cc", path)}
+	}
+	if info.Mode()&0o111 == 0 {
+		problems = append(problems, fmt.Sprintf("%s isn't executable", path))
+	}
+	if data, _ := os.ReadFile(path); string(data) != hookScript(name) {
+		problems = append(problems, fmt.Sprintf("%s is out of date; run `git cc hooks install`", path))
+	}
+	if info, err := os.Stat(path + chainedHookSuffix); err == nil && info.Mode()&0o111 == 0 {
+		problems = append(problems, fmt.Sprintf("%s%s won't run since it isn't executable", path, chainedHookSuffix))
+	}
+	return problems
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) {
+	prepare, _ := cmd.Flags().GetBool("prepare-commit-msg")
+	dir := hooksDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Fatalf("unable to create %s: %+v", dir, err)
+	}
+	installHook(dir, "commit-msg")
+	if prepare {
+		installHook(dir, "prepare-commit-msg")
+	}
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) {
+	dir := hooksDir()
+	uninstallHook(dir, "commit-msg")
+	uninstallHook(dir, "prepare-commit-msg")
+}
+
+// check the commit-msg hook, and the prepare-commit-msg hook if it's installed
+func runHooksCheck(cmd *cobra.Command, args []string) {
+	if check, _ := cmd.Flags().GetBool("check"); !check {
+		_ = cmd.Help()
+		return
+	}
+	dir := hooksDir()
+	problems := checkHook(dir, "commit-msg")
+	if prepare := filepath.Join(dir, "prepare-commit-msg"); isOwnHook(prepare) {
+		problems = append(problems, checkHook(dir, "prepare-commit-msg")...)
+	}
+	if _, err := exec.LookPath("git-cc"); err != nil {
+		problems = append(problems, "git-cc isn't on your PATH, so the hooks can't run it")
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "error: %s\n", problem)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("hooks in %s are installed\n", dir)
+}
+
+func hooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "manage git hooks that check commit messages",
+		Args:  cobra.NoArgs,
+		Run:   runHooksCheck,
+	}
+	cmd.Flags().Bool("check", false, "verify the hooks are installed and up to date")
+	install := &cobra.Command{
+		Use:   "install",
+		Short: "install a commit-msg hook that runs `git cc lint`",
+		Long: `Install a commit-msg hook that runs ` + "`git cc lint`" + ` into the directory git runs
+hooks from, respecting core.hooksPath. An existing hook is renamed with a
+` + chainedHookSuffix + ` suffix and run before git-cc's.`,
+		Args: cobra.NoArgs,
+		Run:  runHooksInstall,
+	}
+	install.Flags().Bool("prepare-commit-msg", false, "also install a prepare-commit-msg hook that opens git-cc's prompt during `git commit`")
+	uninstall := &cobra.Command{
+		Use:   "uninstall",
+		Short: "remove git-cc's hooks, restoring any hooks they replaced",
+		Args:  cobra.NoArgs,
+		Run:   runHooksUninstall,
+	}
+	cmd.AddCommand(install, uninstall)
+	return cmd
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	existing := "#!/bin/sh\necho existing\n"
+	read := func(t *testing.T, path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		return string(data)
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	t.Run("installing chains an existing hook and uninstalling restores it", func(t *testing.T) {
+		dir := t.TempDir()
+		hook := filepath.Join(dir, "commit-msg")
+		if err := os.WriteFile(hook, []byte(existing), 0o755); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		installHook(dir, "commit-msg")
+		if actual := read(t, hook+chainedHookSuffix); actual != existing {
+			fmt.Printf("expected the existing hook to be moved aside, got %q\n", actual)
+			t.Fail()
+		}
+		script := read(t, hook)
+		if !strings.Contains(script, hookMarker) || !strings.Contains(script, `chained="$0`+chainedHookSuffix+`"`) {
+			fmt.Printf("expected a marked hook that runs the chained one, got %q\n", script)
+			t.Fail()
+		}
+		if problems := checkHook(dir, "commit-msg"); len(problems) > 0 {
+			fmt.Printf("expected no problems, got %q\n", problems)
+			t.Fail()
+		}
+		uninstallHook(dir, "commit-msg")
+		if actual := read(t, hook); actual != existing {
+			fmt.Printf("expected the existing hook to be restored, got %q\n", actual)
+			t.Fail()
+		}
+		if exists(hook + chainedHookSuffix) {
+			fmt.Println("expected the chained hook to be moved back")
+			t.Fail()
+		}
+	})
+	t.Run("installing again rewrites git-cc's hook in place", func(t *testing.T) {
+		dir := t.TempDir()
+		hook := filepath.Join(dir, "commit-msg")
+		installHook(dir, "commit-msg")
+		if err := os.WriteFile(hook, []byte("#!/bin/sh\n"+hookMarker+"\nexec git-cc lint\n"), 0o755); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		installHook(dir, "commit-msg")
+		if exists(hook + chainedHookSuffix) {
+			fmt.Println("expected git-cc's own hook not to be chained")
+			t.Fail()
+		}
+		if actual := read(t, hook); actual != hookScript("commit-msg") {
+			fmt.Printf("expected the hook to be updated, got %q\n", actual)
+			t.Fail()
+		}
+		uninstallHook(dir, "commit-msg")
+		if exists(hook) {
+			fmt.Println("expected the hook to be removed")
+			t.Fail()
+		}
+	})
+	t.Run("uninstalling leaves other hooks alone", func(t *testing.T) {
+		dir := t.TempDir()
+		hook := filepath.Join(dir, "commit-msg")
+		if err := os.WriteFile(hook, []byte(existing), 0o755); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		uninstallHook(dir, "commit-msg")
+		if actual := read(t, hook); actual != existing {
+			fmt.Printf("expected the hook to be left alone, got %q\n", actual)
+			t.Fail()
+		}
+	})
+	t.Run("--check", func(t *testing.T) {
+		dir := t.TempDir()
+		hook := filepath.Join(dir, "commit-msg")
+		expectProblem := func(t *testing.T, problem string) {
+			problems := checkHook(dir, "commit-msg")
+			if len(problems) != 1 || !strings.Contains(problems[0], problem) {
+				fmt.Printf("expected a problem like %q, got %q\n", problem, problems)
+				t.Fail()
+			}
+		}
+		expectProblem(t, "is missing")
+		if err := os.WriteFile(hook, []byte(existing), 0o755); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		expectProblem(t, "wasn't installed by git-cc")
+		installHook(dir, "commit-msg")
+		if err := os.Chmod(hook+chainedHookSuffix, 0o644); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		expectProblem(t, "won't run since it isn't executable")
+		if err := os.Chmod(hook+chainedHookSuffix, 0o755); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if err := os.WriteFile(hook, []byte("#!/bin/sh\n"+hookMarker+"\n"), 0o755); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		expectProblem(t, "is out of date")
+		if err := os.Chmod(hook, 0o644); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if problems := checkHook(dir, "commit-msg"); len(problems) != 2 {
+			fmt.Printf("expected the hook to be out of date and not executable, got %q\n", problems)
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/lint"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// the exit code of `git cc lint` when it can't read its config or the message:
+// EX_USAGE from sysexits.h, which is outside the range of ValidationErrors and,
+// unlike 127, doesn't look like a missing command to shells
+const lintFailed = 64
+
+// remove what `git commit --cleanup=strip` would: comment lines, everything
+// below a `commit --verbose` scissors line, and surrounding blank lines.
+func stripComments(message string, commentChar string) string {
+	scissors := commentChar + " ------------------------ >8 ------------------------"
+	lines := []string{}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.TrimRight(line, "\r") == scissors {
+			break
+		}
+		if strings.HasPrefix(line, commentChar) {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// kinds of commits `git cc lint` skips by default, mostly since git writes
+// their messages itself
+var skippable = [...]string{"merges", "fixups", "reverts"}
+
+// why a message shouldn't be checked, if it shouldn't. `parents` is -1 if the
+// message isn't committed yet.
+func skipReason(message string, parents int, skip []string) string {
+	for _, kind := range skip {
+		switch kind {
+		case "merges":
+			if parents > 1 || (parents < 0 && strings.HasPrefix(message, "Merge ")) {
+				return "merge commit"
+			}
+		case "fixups":
+			for _, prefix := range [...]string{"fixup! ", "squash! ", "amend! "} {
+				if strings.HasPrefix(message, prefix) {
+					return strings.TrimSuffix(prefix, "! ") + " commit"
+				}
+			}
+		case "reverts":
+			if strings.HasPrefix(message, "Revert \"") {
+				return "revert commit"
+			}
+		}
+	}
+	return ""
+}
+
+// check that each kind of commit to skip is skippable
+func checkSkip(skip []string) error {
+	for _, kind := range skip {
+		if !slices.Contains(skippable[:], kind) {
+			return fmt.Errorf("unable to skip %q; expected any of %v", kind, skippable)
+		}
+	}
+	return nil
+}
+
+// check each commit in a revision range, oldest first
+func lintRange(cfg *config.Cfg, revRange string, skip []string) ([]lint.Result, error) {
+	commits, err := readCommits(cfg.Repo, revRange)
+	if err != nil {
+		return nil, err
+	}
+	results := []lint.Result{}
+	for _, c := range commits {
+		results = append(results, lintOne(cfg, c.SHA, c.Message, len(c.Parents), skip))
+	}
+	return results, nil
+}
+
+// parse a message the way mainMode does, accepting aliases of types and scopes
+func lintMessage(cfg *config.Cfg, message string) []lint.Violation {
+	cc, _ := parser.ParseAsMuchOfCCAsPossible(message)
+	cc.Type = cfg.CanonicalType(cc.Type)
+	cc.Scope = cfg.CanonicalScope(cc.Scope)
+	return lint.Lint(cfg, cc, message)
+}
+
+func runLint(cmd *cobra.Command, args []string) {
+	file, _ := cmd.Flags().GetString("file")
+	revRange, _ := cmd.Flags().GetString("range")
+	format, _ := cmd.Flags().GetString("format")
+	skip, _ := cmd.Flags().GetStringSlice("skip")
+	if !slices.Contains(lint.ReportFormats[:], format) {
+		fmt.Fprintf(os.Stderr, "unknown format %q; expected one of %v\n", format, lint.ReportFormats)
+		os.Exit(lintFailed)
+	}
+	if err := checkSkip(skip); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(lintFailed)
+	}
+	cfg, err := config.Init(true) // linting doesn't need staged changes
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(lintFailed)
+	}
+	if err := lint.Validate(cfg.Rules); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", cfg.ConfigFile, err)
+		os.Exit(lintFailed)
+	}
+	var results []lint.Result
+	if revRange != "" {
+		if results, err = lintRange(cfg, revRange, skip); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(lintFailed)
+		}
+	} else {
+		var data []byte
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(lintFailed)
+		}
+		message := stripComments(string(data), cfg.CommentChar())
+		results = append(results, lintOne(cfg, "", message, -1, skip))
+	}
+	// text is for people, so it goes alongside other diagnostics
+	out := os.Stdout
+	if format == "text" {
+		out = os.Stderr
+	}
+	if err := lint.WriteReport(out, format, results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(lintFailed)
+	}
+	var exitCode ValidationErrors
+	for _, r := range results {
+		exitCode |= toValidationErrors(r.Violations)
+	}
+	os.Exit(int(exitCode))
+}
+
+func lintOne(cfg *config.Cfg, commit string, message string, parents int, skip []string) lint.Result {
+	header, _, _ := strings.Cut(message, "\n")
+	result := lint.Result{Commit: commit, Header: header}
+	if result.Skipped = skipReason(message, parents, skip); result.Skipped == "" {
+		result.Violations = lintMessage(cfg, message)
+	}
+	return result
+}
+
+func lintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "check commit messages without committing",
+		Long: `Check a commit message file, stdin, or each commit in a range against the
+configured types, scopes, and rules. Comment lines in message files are
+ignored. By default, merge commits, fixup!/squash!/amend! commits, and the
+reverts git writes (Revert "...") are skipped; see --skip.
+
+Exits 0 if there are no errors. Otherwise, the exit code is the sum of:
+  1   invalid type
+  2   missing type
+  4   invalid scope
+  8   missing description
+  16  any other rule violation
+If the config or the messages can't be read, the exit code is 64.`,
+		Example: `  git cc lint --file .git/COMMIT_EDITMSG
+  git cc lint --range origin/main..HEAD --format github`,
+		Args: cobra.NoArgs,
+		Run:  runLint,
+	}
+	flags := cmd.Flags()
+	flags.StringP("file", "f", "-", "the commit message file to check, e.g. .git/COMMIT_EDITMSG; - reads stdin")
+	flags.String("range", "", "check each commit in a revision range, e.g. origin/main..HEAD")
+	flags.String("format", "text", fmt.Sprintf("how to report problems; one of %s", strings.Join(lint.ReportFormats[:], ", ")))
+	flags.StringSlice("skip", skippable[:], "which of merges, fixups, and reverts not to check; pass --skip= to check every commit")
+	cmd.MarkFlagsMutuallyExclusive("file", "range")
+	return cmd
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+func TestStripComments(t *testing.T) {
+	for _, c := range []struct {
+		message     string
+		commentChar string
+		expected    string
+	}{
+		{"feat: add x\n# Please enter the commit message\n", "#", "feat: add x"},
+		{"\n\nfeat: add x  \n\nbody\n\n", "#", "feat: add x\n\nbody"},
+		{"feat: add #1\n; a comment\n", ";", "feat: add #1"},
+		{"feat: add x\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n", "#", "feat: add x"},
+	} {
+		if actual := stripComments(c.message, c.commentChar); actual != c.expected {
+			fmt.Printf("%q: expected %q, got %q\n", c.message, c.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	for _, c := range []struct {
+		message  string
+		parents  int
+		skip     []string
+		expected string
+	}{
+		{"Merge branch 'x'", 2, skippable[:], "merge commit"},
+		{"Merge branch 'x'", -1, skippable[:], "merge commit"},
+		{"Merge the parsers", 1, skippable[:], ""},
+		{"fixup! feat: add x", 1, skippable[:], "fixup commit"},
+		{"squash! feat: add x", 1, skippable[:], "squash commit"},
+		{"amend! feat: add x", 1, skippable[:], "amend commit"},
+		{"Revert \"feat: add x\"", 1, skippable[:], "revert commit"},
+		{"revert: add x", 1, skippable[:], ""},
+		{"fixup! feat: add x", 1, []string{"merges"}, ""},
+		{"Merge branch 'x'", 2, []string{}, ""},
+	} {
+		if actual := skipReason(c.message, c.parents, c.skip); actual != c.expected {
+			fmt.Printf("%q with %d parents, skipping %v: expected %q, got %q\n", c.message, c.parents, c.skip, c.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestLintRange(t *testing.T) {
+	fake, cfg := fakeRepo(t, "")
+	fake.History = []git.Commit{
+		{SHA: "f", Message: "Revert \"feat: add x\""},
+		{SHA: "e", Message: "fixup! feat: add x"},
+		{SHA: "d", Message: "Merge branch 'y'", Parents: []string{"c", "b"}},
+		{SHA: "c", Message: "Add y"},
+		{SHA: "b", Message: "feat: add x"},
+		{SHA: "a", Message: "chore: init", Parents: []string{}},
+	}
+	summarize := func(revRange string, skip []string) string {
+		results, err := lintRange(cfg, revRange, skip)
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		summary := []string{}
+		for _, r := range results {
+			outcome := "ok"
+			if r.Skipped != "" {
+				outcome = "skipped"
+			} else if len(r.Violations) > 0 {
+				outcome = "failed"
+			}
+			summary = append(summary, r.Commit+":"+outcome)
+		}
+		return strings.Join(summary, " ")
+	}
+	for _, c := range []struct {
+		revRange string
+		skip     []string
+		expected string
+	}{
+		{"a..HEAD", skippable[:], "b:ok c:failed d:skipped e:skipped f:skipped"},
+		{"b..d", skippable[:], "c:failed d:skipped"},
+		{"d..HEAD", []string{"fixups"}, "e:skipped f:failed"},
+		{"a..HEAD", []string{}, "b:ok c:failed d:failed e:failed f:failed"},
+	} {
+		if actual := summarize(c.revRange, c.skip); actual != c.expected {
+			fmt.Printf("%s skipping %v: expected %q, got %q\n", c.revRange, c.skip, c.expected, actual)
+			t.Fail()
+		}
+	}
+	if _, err := lintRange(cfg, "nonexistent..HEAD", skippable[:]); err == nil {
+		fmt.Println("expected an unknown revision to fail")
+		t.Fail()
+	}
+	if err := checkSkip([]string{"merges", "tags"}); err == nil {
+		fmt.Println("expected tags not to be skippable")
+		t.Fail()
+	}
+}
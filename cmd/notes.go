@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/changelog"
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+)
+
+// split a range like `v1.2.0..HEAD` into its ends. A lone ref ends a range
+// starting at the latest tag before it.
+func splitRange(repo git.Repo, revRange string, match string) (from string, to string) {
+	from, to, found := strings.Cut(revRange, "..")
+	if !found {
+		from, to = "", revRange
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	if !found {
+		from = latestTag(repo, to, match, exactTag(repo, to, match))
+	}
+	return from, to
+}
+
+func runNotes(cmd *cobra.Command, args []string) {
+	flags := cmd.Flags()
+	templateFile, _ := flags.GetString("template")
+	format, _ := flags.GetString("format")
+	version, _ := flags.GetString("version")
+	prefix, _ := flags.GetString("tag-prefix")
+	pkg := packageFromFlags(cmd)
+	if !flags.Changed("tag-prefix") {
+		prefix = pkg.tagPrefix()
+	}
+	var tmpl *template.Template
+	var err error
+	if templateFile != "" {
+		text, err := os.ReadFile(templateFile)
+		if err != nil {
+			log.Fatalf("unable to read %s: %+v", templateFile, err)
+		}
+		tmpl, err = changelog.ParseTemplate(filepath.Base(templateFile), string(text))
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+	} else if tmpl, err = changelog.BuiltinTemplate(format); err != nil {
+		log.Fatalf("%s", err)
+	}
+	revRange := "HEAD"
+	if len(args) > 0 {
+		revRange = args[0]
+	}
+	cfg, err := config.Init(true)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	from, to := splitRange(cfg.Repo, revRange, prefix+"*")
+	release := changelog.Release{Name: version, Ref: to, Previous: from, Date: time.Now().Format("2006-01-02")}
+	if tag := exactTag(cfg.Repo, to, prefix+"*"); tag != "" {
+		release.Ref, release.Date = tag, commitDate(cfg.Repo, tag)
+		if release.Name == "" {
+			release.Name = tag
+		}
+	}
+	if release.Name == "" {
+		if next, _, err := nextVersion(cfg, pkg, to, prefix, ""); err == nil {
+			release.Name = next
+		} else {
+			release.Name = changelog.Unreleased
+		}
+	}
+	commitRange := to
+	if from != "" {
+		commitRange = from + ".." + to
+	}
+	commits, err := pkg.commits(cfg, commitRange)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	for _, c := range commits {
+		release.Commits = append(release.Commits, changelog.Commit{Hash: c.SHA, Message: c.Message, Author: c.Author, Email: c.Email})
+	}
+	notes := changelog.Collect(cfg, changelogLinks(cfg), release)
+	if err := tmpl.Execute(os.Stdout, notes); err != nil {
+		log.Fatalf("%s", err)
+	}
+}
+
+func notesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notes [<from>..<to>]",
+		Short: "render release notes through a template",
+		Long: `Render the release notes of the commits in a range through a Go text/template,
+either a built-in one or your own. The range defaults to the commits since the
+latest version tag. The notes are named after the tag of <to>, if it's tagged,
+and otherwise the next version the commits call for.
+
+Templates get these fields, described in detail in the README:
+
+  .Version .Previous .Date .CompareURL  about the release
+  .Breaking                             a change per breaking change note
+  .Groups                               changelog sections with .Title, .Types,
+                                        .Changes, and .Scopes
+  .Commits                              every conventional commit
+  .Other                                every other commit
+  .References .Authors
+
+and the functions join, lower, upper, trim, markdownLink, and markdownLinks.`,
+		Example: `  git cc notes v1.2.0..HEAD --template notes.tmpl
+  git cc notes --format keep-a-changelog`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runNotes,
+	}
+	flags := cmd.Flags()
+	flags.String("template", "", "a text/template file to render the notes with")
+	flags.String("format", "markdown", "a built-in template: "+strings.Join(changelog.Formats[:], ", "))
+	flags.String("version", "", "the version to name the notes after, rather than computing it")
+	flags.String("tag-prefix", "v", "the prefix of version tags; defaults to DIR/v with --package DIR")
+	addPackageFlags(flags)
+	cmd.MarkFlagsMutuallyExclusive("template", "format")
+	return cmd
+}
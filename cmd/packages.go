@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/pkg/parser"
+	"github.com/skalt/git-cc/pkg/semver"
+)
+
+// a package of a monorepo that's versioned on its own, e.g. `pkg/parser`
+// tagged like `pkg/parser/v1.4.0`. A nil package is the whole repo.
+type monorepoPackage struct {
+	// relative to the root of the repo, with forward slashes
+	Dir string
+	// commits with these scopes belong to the package even if they don't
+	// change any files in Dir
+	Scopes []string
+}
+
+func addPackageFlags(flags *pflag.FlagSet) {
+	flags.String("package", "", "only consider commits to the package in this directory relative to the root of the repo, e.g. pkg/parser, and its tags, e.g. pkg/parser/v1.4.0")
+	flags.StringSlice("package-scope", []string{}, "also consider commits with these scopes part of --package")
+}
+
+func packageFromFlags(cmd *cobra.Command) *monorepoPackage {
+	dir, _ := cmd.Flags().GetString("package")
+	scopes, _ := cmd.Flags().GetStringSlice("package-scope")
+	if dir == "" {
+		return nil
+	}
+	return &monorepoPackage{Dir: path.Clean(filepath.ToSlash(dir)), Scopes: scopes}
+}
+
+// the prefix of the package's version tags, following the Go convention for
+// modules in subdirectories; see https://go.dev/ref/mod#vcs-version
+func (p *monorepoPackage) tagPrefix() string {
+	if p == nil {
+		return "v"
+	}
+	return p.Dir + "/v"
+}
+
+// the commits in a range that belong to the package
+func (p *monorepoPackage) commits(cfg *config.Cfg, revRange string) ([]git.Commit, error) {
+	if p == nil {
+		return readCommits(cfg.Repo, revRange)
+	}
+	// Dir is relative to the root, wherever git-cc runs
+	pathspec := ":(top)" + p.Dir
+	if len(p.Scopes) == 0 {
+		return readCommits(cfg.Repo, revRange, pathspec)
+	}
+	changed, err := readCommits(cfg.Repo, revRange, pathspec)
+	if err != nil {
+		return nil, err
+	}
+	changedPackage := map[string]bool{}
+	for _, c := range changed {
+		changedPackage[c.SHA] = true
+	}
+	all, err := readCommits(cfg.Repo, revRange)
+	if err != nil {
+		return nil, err
+	}
+	result := []git.Commit{}
+	for _, c := range all {
+		cc, _ := parser.ParseAsMuchOfCCAsPossible(c.Message)
+		if changedPackage[c.SHA] || slices.Contains(p.Scopes, cfg.CanonicalScope(cc.Scope)) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// the directory of the package's go.mod, if any
+func (p *monorepoPackage) dir(cfg *config.Cfg) string {
+	root := cfg.RepoRoot()
+	if p == nil {
+		return root
+	}
+	return filepath.Join(root, filepath.FromSlash(p.Dir))
+}
+
+var moduleLine = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?`)
+
+// the module path declared in a directory's go.mod, or "" if there's none
+func goModulePath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	if match := moduleLine.FindSubmatch(data); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+var majorSuffix = regexp.MustCompile(`/v\d+$`)
+
+// explain why a version can't be released from the Go module in a directory,
+// if it can't: from v2 on, a module's path must end in its major version, e.g.
+// `/v2`. The module may live in the directory or in a `vN` subdirectory of
+// it; see https://go.dev/doc/modules/major-version
+func goMajorVersionProblem(dir string, next semver.Version) string {
+	module := goModulePath(dir)
+	if module == "" || next.Major < 2 {
+		return ""
+	}
+	suffix := fmt.Sprintf("/v%d", next.Major)
+	if strings.HasSuffix(module, suffix) {
+		return ""
+	}
+	if major := goModulePath(filepath.Join(dir, suffix[1:])); strings.HasSuffix(major, suffix) {
+		return ""
+	}
+	return fmt.Sprintf(
+		"v%d is a new major version, so the module path %s must become %s; see https://go.dev/doc/modules/major-version",
+		next.Major, module, majorSuffix.ReplaceAllString(module, "")+suffix,
+	)
+}
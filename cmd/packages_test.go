@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/pkg/semver"
+)
+
+func TestTagPrefix(t *testing.T) {
+	var repo *monorepoPackage
+	if prefix := repo.tagPrefix(); prefix != "v" {
+		fmt.Printf("expected the whole repo's tags to start with v, got %q\n", prefix)
+		t.Fail()
+	}
+	if prefix := (&monorepoPackage{Dir: "pkg/parser"}).tagPrefix(); prefix != "pkg/parser/v" {
+		fmt.Printf("expected pkg/parser/v, got %q\n", prefix)
+		t.Fail()
+	}
+}
+
+func TestPackageCommits(t *testing.T) {
+	fake, cfg := fakeRepo(t, "")
+	fake.History = []git.Commit{
+		{SHA: "d", Message: "chore: d"},
+		{SHA: "c", Message: "feat(semver): c"},
+		{SHA: "b", Message: "docs(parser): b"},
+		{SHA: "a", Message: "fix: a"},
+	}
+	fake.Changes = map[string][]string{
+		"a": {"pkg/parser/parser.go"},
+		"b": {"README.md"},
+		"c": {"pkg/semver/semver.go"},
+		"d": {"README.md", "pkg/parser/README.md"},
+	}
+	test := func(pkg *monorepoPackage, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			commits, err := pkg.commits(cfg, "HEAD")
+			if err != nil {
+				fmt.Println(err)
+				t.FailNow()
+			}
+			shas := []string{}
+			for _, c := range commits {
+				shas = append(shas, c.SHA)
+			}
+			if actual := strings.Join(shas, " "); actual != expected {
+				fmt.Printf("expected %q, got %q\n", expected, actual)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("the whole repo", test(nil, "a b c d"))
+	t.Run("files in the directory", test(&monorepoPackage{Dir: "pkg/parser"}, "a d"))
+	t.Run("or a package scope", test(&monorepoPackage{Dir: "pkg/parser", Scopes: []string{"parser"}}, "a b d"))
+}
+
+func TestGoMajorVersionProblem(t *testing.T) {
+	module := func(dir string, path string) string {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+path+"\n\ngo 1.24\n"), 0o644); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		return dir
+	}
+	v1 := module(t.TempDir(), "example.com/m")
+	test := func(dir string, major int, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			problem := goMajorVersionProblem(dir, semver.Version{Major: major})
+			if (expected == "") != (problem == "") || !strings.Contains(problem, expected) {
+				fmt.Printf("expected a problem mentioning %q, got %q\n", expected, problem)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("v1 needs no suffix", test(v1, 1, ""))
+	t.Run("v2 needs a suffix", test(v1, 2, "example.com/m/v2"))
+	t.Run("v3 replaces v2's suffix", test(module(t.TempDir(), "example.com/m/v2"), 3, "must become example.com/m/v3"))
+	t.Run("a suffixed module path", test(module(t.TempDir(), "example.com/m/v2"), 2, ""))
+	withSubdirectory := module(t.TempDir(), "example.com/m")
+	module(filepath.Join(withSubdirectory, "v2"), "example.com/m/v2")
+	t.Run("a major version subdirectory", test(withSubdirectory, 2, ""))
+	t.Run("not a Go module", test(t.TempDir(), 2, ""))
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/lint"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// the comment lines of a message git prepared, including everything below a
+// `commit --verbose` scissors line, which git strips after editing.
+func commentBlock(message string, commentChar string) string {
+	scissors := commentChar + " ------------------------ >8 ------------------------"
+	lines := []string{}
+	all := strings.Split(message, "\n")
+	for i, line := range all {
+		if strings.TrimRight(line, "\r") == scissors {
+			lines = append(lines, all[i:]...)
+			break
+		}
+		if strings.HasPrefix(line, commentChar) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// whether to prompt given the source of the message git prepared. Messages
+// from -m, -F, merges, squashes, and existing commits (-c, -C, --amend) are
+// left alone; templates are used to prefill the prompt.
+// See https://git-scm.com/docs/githooks#_prepare_commit_msg
+func shouldPrompt(source string) bool {
+	return source == "" || source == "template"
+}
+
+// runs as git's prepare-commit-msg hook: prompt for a conventional commit and
+// write it into the message file git is about to open in an editor, keeping
+// git's comments. Unlike mainMode, this never runs `git commit` itself.
+func runPrepareCommitMsg(cmd *cobra.Command, args []string) {
+	path := args[0]
+	source := ""
+	if len(args) > 1 {
+		source = args[1]
+	}
+	if !shouldPrompt(source) {
+		return
+	}
+	// git runs hooks without a stdin, so talk to the terminal directly. Without
+	// one, e.g. in an IDE, leave the message to the editor.
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return
+	}
+	defer tty.Close()
+	cfg, err := config.Init(false)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	if err := lint.Validate(cfg.Rules); err != nil {
+		log.Fatalf("%s: %s", cfg.ConfigFile, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("unable to read %s: %+v", path, err)
+	}
+	commentChar := cfg.CommentChar()
+	cc, _ := parser.ParseAsMuchOfCCAsPossible(stripComments(string(data), commentChar))
+	cc.Type = cfg.CanonicalType(cc.Type)
+	cc.Scope = cfg.CanonicalScope(cc.Scope)
+	var ticket string
+	if branch, err := cfg.Repo.Branch(); err == nil {
+		ticket = prefillFromBranch(cc, cfg, branch)
+	}
+	if cfg.TicketHeaderFormat == "" {
+		addTicket(cc, cfg, ticket)
+	}
+	message, ok := promptForMessage(initialModel(cc, cfg), ticket, tea.WithInput(tty), tea.WithOutput(tty))
+	if !ok {
+		os.Exit(1) // no submission; abort the commit
+	}
+	if comments := commentBlock(string(data), commentChar); comments != "" {
+		message = strings.TrimRight(message, "\n") + "\n\n" + comments + "\n"
+	}
+	if err := os.WriteFile(path, []byte(message), 0o644); err != nil {
+		log.Fatalf("unable to write to file %s: %+v", path, err)
+	}
+}
+
+func prepareCommitMsgCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prepare-commit-msg FILE [SOURCE [SHA]]",
+		Short: "prompt for a commit message from git's prepare-commit-msg hook",
+		Long: `Prompt for a conventional commit on the terminal and write it into FILE,
+keeping git's comments. Meant to run as git's prepare-commit-msg hook; see
+` + "`git cc hooks install --prepare-commit-msg`" + `. Does nothing when SOURCE is
+message, merge, squash, or commit, or when there's no terminal.`,
+		Args: cobra.RangeArgs(1, 3),
+		Run:  runPrepareCommitMsg,
+	}
+}
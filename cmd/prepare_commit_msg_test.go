@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShouldPrompt(t *testing.T) {
+	for source, expected := range map[string]bool{
+		"":         true,
+		"template": true,
+		"message":  false,
+		"merge":    false,
+		"squash":   false,
+		"commit":   false,
+	} {
+		if actual := shouldPrompt(source); actual != expected {
+			fmt.Printf("%q: expected %v, got %v\n", source, expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestCommentBlock(t *testing.T) {
+	scissors := "# ------------------------ >8 ------------------------"
+	for _, c := range []struct {
+		name, message, commentChar, expected string
+	}{
+		{"no comments", "feat: add x\n\nbody\n", "#", ""},
+		{
+			"comments among the message",
+			"feat: add x\n# Please enter the commit message\n\n# On branch main\n",
+			"#",
+			"# Please enter the commit message\n# On branch main",
+		},
+		{
+			"everything below the scissors",
+			"\n# Please enter the commit message\n" + scissors + "\n# Do not modify\ndiff --git a/x b/x\n+x\n",
+			"#",
+			"# Please enter the commit message\n" + scissors + "\n# Do not modify\ndiff --git a/x b/x\n+x\n",
+		},
+		{"CRLF scissors", "\n" + scissors + "\r\ndiff\r\n", "#", scissors + "\r\ndiff\r\n"},
+		{
+			"core.commentChar",
+			"feat: #1\n; On branch main\n# not a comment\n",
+			";",
+			"; On branch main",
+		},
+	} {
+		if actual := commentBlock(c.message, c.commentChar); actual != c.expected {
+			fmt.Printf("%s: expected %q, got %q\n", c.name, c.expected, actual)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/lint"
+	"github.com/skalt/git-cc/internal/utils"
+)
+
+// commit with the message and authorship of another commit, like
+// `git commit -c` or `git commit -C`. A message reused with -C is committed
+// as-is if it's valid; otherwise, it's revised in the TUI.
+func reuseMode(cmd *cobra.Command, args []string, cfg *config.Cfg) {
+	flags := cmd.Flags()
+	if message, _ := flags.GetStringArray("message"); len(message) > 0 || len(args) > 0 {
+		log.Fatal("-m|--message is incompatible with -c|--reedit-message and -C|--reuse-message")
+	}
+	source, _ := flags.GetString("reedit-message")
+	reuse := flags.Changed("reuse-message")
+	if reuse {
+		source, _ = flags.GetString("reuse-message")
+	}
+	commit, err := readCommit(cfg.Repo, source)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	if !flags.Changed("author") {
+		utils.Check(flags.Set("author", commit.Author+" <"+commit.Email+">"))
+	}
+	if !flags.Changed("date") {
+		utils.Check(flags.Set("date", commit.Date))
+	}
+	if amend, _ := flags.GetBool("amend"); !amend {
+		requireStagedChanges(cmd, cfg)
+	}
+	commitParams := getGitCommitCmd(cmd, cfg)
+
+	if reuse && reusableAsIs(cfg, commit.Message) {
+		doCommit(cfg, commit.Message, commitParams)
+		return
+	}
+	message, ok := promptForMessage(revisionModel(parseRevision(commit.Message), cfg), "")
+	if !ok {
+		os.Exit(1) // no submission
+	}
+	doCommit(cfg, message, commitParams)
+}
+
+// whether a message reused with -C can be committed without revising it,
+// printing any warnings about it
+func reusableAsIs(cfg *config.Cfg, message string) bool {
+	violations := lintMessage(cfg, message)
+	if lint.HasErrors(violations) {
+		return false
+	}
+	printViolations(violations)
+	return true
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+func TestParseRevision(t *testing.T) {
+	for _, c := range []struct {
+		message, commitType, scope, description string
+	}{
+		{"feat(api): add x\n\nbody", "feat", "api", "add x"},
+		{"fix: y", "fix", "", "y"},
+		{"Fixed the login", "", "", "Fixed the login"},
+		{"Merge branch 'main'", "", "", "Merge branch 'main'"},
+	} {
+		cc := parseRevision(c.message)
+		if cc.Type != c.commitType || cc.Scope != c.scope || cc.Description != c.description {
+			fmt.Printf("%q: expected %q %q %q, got %+v\n", c.message, c.commitType, c.scope, c.description, cc)
+			t.Fail()
+		}
+	}
+}
+
+func TestReuseMode(t *testing.T) {
+	reuse := func(t *testing.T, message string, args ...string) *git.Fake {
+		fake, cfg := fakeRepo(t, "")
+		fake.History = []git.Commit{{
+			SHA: "abc1234", Author: "Ada", Email: "ada@example.com",
+			Date: "2024-01-02T03:04:05+00:00", Message: message,
+		}}
+		cmd := Cmd("", "", "")
+		if err := cmd.ParseFlags(args); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		reuseMode(cmd, cmd.Flags().Args(), cfg)
+		return fake
+	}
+	t.Run("-C commits a valid message verbatim", func(t *testing.T) {
+		fake := reuse(t, "feat: add x\n\nbody", "-C", "HEAD")
+		if len(fake.Committed) != 1 {
+			fmt.Printf("expected one commit, got %+v\n", fake.Committed)
+			t.FailNow()
+		}
+		if actual := strings.TrimSpace(fake.Committed[0].Message); actual != "feat: add x\n\nbody" {
+			fmt.Printf("unexpected message %q\n", actual)
+			t.Fail()
+		}
+		for _, arg := range []string{"--no-edit", "--author=Ada <ada@example.com>", "--date=2024-01-02T03:04:05+00:00"} {
+			if !slices.Contains(fake.Committed[0].Args, arg) {
+				fmt.Printf("expected %s, got %q\n", arg, fake.Committed[0].Args)
+				t.Fail()
+			}
+		}
+	})
+	t.Run("--author and --date override the reused ones", func(t *testing.T) {
+		fake := reuse(t, "feat: add x", "-C", "HEAD", "--author=Bo <bo@example.com>", "--date=now")
+		if len(fake.Committed) != 1 {
+			fmt.Printf("expected one commit, got %+v\n", fake.Committed)
+			t.FailNow()
+		}
+		for _, arg := range []string{"--author=Bo <bo@example.com>", "--date=now"} {
+			if !slices.Contains(fake.Committed[0].Args, arg) {
+				fmt.Printf("expected %s, got %q\n", arg, fake.Committed[0].Args)
+				t.Fail()
+			}
+		}
+		if len(slices.DeleteFunc(slices.Clone(fake.Committed[0].Args), func(arg string) bool {
+			return !strings.HasPrefix(arg, "--author=") && !strings.HasPrefix(arg, "--date=")
+		})) != 2 {
+			fmt.Printf("expected one --author and one --date, got %q\n", fake.Committed[0].Args)
+			t.Fail()
+		}
+	})
+	t.Run("non-conventional messages go to the TUI", func(t *testing.T) {
+		_, cfg := fakeRepo(t, "")
+		if reusableAsIs(cfg, "Fixed the login") {
+			fmt.Println("expected a non-conventional message to need revising")
+			t.Fail()
+		}
+		if !reusableAsIs(cfg, "fix: the login") {
+			fmt.Println("expected a conventional message to be reusable")
+			t.Fail()
+		}
+		m := revisionModel(parseRevision("Fixed the login"), cfg)
+		if m.viewing != commitTypeIndex {
+			fmt.Printf("expected to start at the type, got %d\n", m.viewing)
+			t.Fail()
+		}
+		if m.commit[shortDescriptionIndex] != "Fixed the login" {
+			fmt.Printf("expected the description to be kept, got %q\n", m.commit[shortDescriptionIndex])
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+)
+
+// the first protected ref that already contains a commit, or "" if there's
+// none. Patterns are globs of branches, remote-tracking branches, or tags, or
+// else revisions like `@{upstream}`.
+func protectingRef(repo git.Repo, patterns []string, commit string) string {
+	for _, pattern := range patterns {
+		refs, err := repo.ListRefs(pattern)
+		if err != nil {
+			continue
+		}
+		if len(refs) == 0 {
+			if _, err := repo.ResolveRef(pattern); err == nil {
+				refs = []string{pattern}
+			}
+		}
+		for _, ref := range refs {
+			if repo.IsAncestor(commit, ref) {
+				return ref
+			}
+		}
+	}
+	return ""
+}
+
+// write a copy of a commit with its parents replaced by their rewritten
+// versions and, if one's given, a new message. Everything else, including
+// the author, committer, and their dates, stays the same; signatures are
+// dropped since they'd no longer match.
+func rewriteCommit(repo git.Repo, commit string, rewritten map[string]string, message string) (string, error) {
+	raw, err := repo.CatCommit(commit)
+	if err != nil {
+		return "", err
+	}
+	header, body, _ := strings.Cut(raw, "\n\n")
+	lines := []string{}
+	inSignature := false
+	for _, line := range strings.Split(header, "\n") {
+		if inSignature && strings.HasPrefix(line, " ") {
+			continue // a continuation of the signature
+		}
+		inSignature = strings.HasPrefix(line, "gpgsig")
+		if inSignature {
+			continue
+		}
+		if parent, ok := strings.CutPrefix(line, "parent "); ok && rewritten[parent] != "" {
+			line = "parent " + rewritten[parent]
+		}
+		lines = append(lines, line)
+	}
+	if message != "" {
+		body = strings.TrimRight(message, "\n") + "\n"
+	}
+	return repo.WriteCommit(strings.Join(lines, "\n") + "\n\n" + body)
+}
+
+// refuse to rewrite history from a commit onward if that'd change a protected
+// ref's history or flatten a merge
+func checkRewritable(cfg *config.Cfg, commits []git.Commit) error {
+	short := shortSHA(commits[0].SHA)
+	if ref := protectingRef(cfg.Repo, cfg.ProtectedRefs, commits[0].SHA); ref != "" {
+		return fmt.Errorf("refusing to rewrite %s: it's already part of the protected ref %s", short, ref)
+	}
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			return fmt.Errorf("refusing to rewrite %s: %s since then is a merge", short, shortSHA(c.SHA))
+		}
+	}
+	return nil
+}
+
+// abbreviate a commit hash like `git log --oneline` does
+func shortSHA(sha string) string {
+	return sha[:min(7, len(sha))]
+}
+
+// copy a linear run of commits ending at HEAD, oldest first, giving some new
+// messages, then point HEAD at the copy. Commits before the first new message
+// are kept as-is. Returns the rewritten hashes of the commits.
+func rewriteHistory(repo git.Repo, commits []git.Commit, messages map[string]string, reason string) (map[string]string, error) {
+	rewritten := map[string]string{}
+	oldHead, newHead := "", ""
+	for _, c := range commits {
+		if len(rewritten) == 0 && messages[c.SHA] == "" {
+			continue
+		}
+		hash, err := rewriteCommit(repo, c.SHA, rewritten, messages[c.SHA])
+		if err != nil {
+			return nil, err
+		}
+		rewritten[c.SHA] = hash
+		oldHead, newHead = c.SHA, hash
+	}
+	if newHead == "" {
+		return rewritten, nil
+	}
+	// only move HEAD if nothing else moved it in the meantime
+	return rewritten, repo.UpdateRef("HEAD", newHead, oldHead, reason)
+}
+
+func runReword(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	cfg, err := config.Init(dryRun)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	target, err := readCommit(cfg.Repo, args[0])
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	short := target.SHA[:7]
+	if !cfg.Repo.IsAncestor(target.SHA, "HEAD") {
+		log.Fatalf("%s isn't part of the current branch", short)
+	}
+	descendants, err := readCommits(cfg.Repo, target.SHA+"..HEAD")
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	commits := append([]git.Commit{target}, descendants...)
+	if err := checkRewritable(cfg, commits); err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	message, ok := promptForMessage(revisionModel(parseRevision(target.Message), cfg), "")
+	if !ok {
+		os.Exit(1) // no submission
+	}
+	if strings.TrimSpace(message) == strings.TrimSpace(target.Message) {
+		fmt.Println("the message is unchanged; nothing to rewrite")
+		return
+	}
+	if dryRun {
+		fmt.Println(message)
+		fmt.Printf("would rewrite %s and the %d commits since\n", short, len(descendants))
+		return
+	}
+
+	rewritten, err := rewriteHistory(cfg.Repo, commits, map[string]string{target.SHA: message}, "git-cc reword "+short)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	fmt.Printf("reworded %s as %s, rewriting the %d commits since\n", short, rewritten[target.SHA][:7], len(descendants))
+}
+
+func rewordCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reword <commit>",
+		Short: "revise the message of a past commit",
+		Long: `Revise the message of a commit on the current branch in the TUI, then rewrite
+the commits since then on top of it. Only messages change: the rewritten commits
+keep their content, authors, committers, and dates, and the working tree and
+index are left alone.
+
+Commits that are already part of a protected ref, as set by "protected_refs" in
+the config file, can't be reworded, and neither can commits followed by merges.`,
+		Example: `  git cc reword HEAD~3`,
+		Args:    cobra.ExactArgs(1),
+		Run:     runReword,
+	}
+	cmd.Flags().Bool("dry-run", false, "print the revised message rather than rewriting anything")
+	return cmd
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+)
+
+// a signed commit, committed by someone other than its author at another time
+const signedCommit = `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+parent a
+author A U Thor <au@thor.example> 1700000000 +0100
+committer C O Mitter <co@mitter.example> 1700000500 -0500
+gpgsig -----BEGIN PGP SIGNATURE-----
+ 
+ iQEzBAABCAAdFiEE
+ -----END PGP SIGNATURE-----
+
+Fix b
+`
+
+// a repo's config and the commits after its root, which can be reworded
+type rewordFixture struct {
+	cfg     *config.Cfg
+	commits []git.Commit
+}
+
+// a repo whose second commit is signed and isn't conventional
+func rewordRepo(t *testing.T, configFile string) (*git.Fake, *rewordFixture) {
+	fake, cfg := fakeRepo(t, configFile)
+	fake.History = []git.Commit{
+		{SHA: "c", Message: "feat: c"},
+		{SHA: "b", Message: "Fix b"},
+		{SHA: "a", Message: "chore: a", Parents: []string{}},
+	}
+	fake.Objects = map[string]string{"b": signedCommit}
+	commits, err := readCommits(fake, "a..HEAD")
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	return fake, &rewordFixture{cfg: cfg, commits: commits}
+}
+
+func TestRewriteHistory(t *testing.T) {
+	t.Run("only messages change", func(t *testing.T) {
+		fake, f := rewordRepo(t, "")
+		rewritten, err := rewriteHistory(fake, f.commits, map[string]string{"b": "fix: b"}, "test")
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		b, c := rewritten["b"], rewritten["c"]
+		if b == "" || c == "" || fake.History[0].SHA != c {
+			fmt.Printf("expected b and c to be rewritten and HEAD to move, got %v and %v\n", rewritten, fake.History)
+			t.FailNow()
+		}
+		raw := fake.Objects[b]
+		for _, expected := range []string{
+			"parent a\n",
+			"author A U Thor <au@thor.example> 1700000000 +0100\n",
+			"committer C O Mitter <co@mitter.example> 1700000500 -0500\n",
+			"\n\nfix: b\n",
+		} {
+			if !strings.Contains(raw, expected) {
+				fmt.Printf("expected %q in\n%s\n", expected, raw)
+				t.Fail()
+			}
+		}
+		if strings.Contains(raw, "gpgsig") || strings.Contains(raw, "PGP") {
+			fmt.Printf("expected the signature to be dropped:\n%s\n", raw)
+			t.Fail()
+		}
+		if !strings.Contains(fake.Objects[c], "parent "+b+"\n") {
+			fmt.Printf("expected c to follow the rewritten b:\n%s\n", fake.Objects[c])
+			t.Fail()
+		}
+		messages := []string{}
+		for _, commit := range fake.History {
+			messages = append(messages, commit.Message)
+		}
+		if expected := []string{"feat: c", "fix: b", "chore: a"}; !slices.Equal(messages, expected) {
+			fmt.Printf("expected %q, got %q\n", expected, messages)
+			t.Fail()
+		}
+	})
+	t.Run("HEAD moved meanwhile", func(t *testing.T) {
+		fake, f := rewordRepo(t, "")
+		if err := fake.Commit("feat: d", "--allow-empty"); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		head := fake.History[0].SHA
+		if _, err := rewriteHistory(fake, f.commits, map[string]string{"b": "fix: b"}, "test"); err == nil {
+			fmt.Println("expected the rewrite to fail since HEAD moved")
+			t.Fail()
+		}
+		if fake.History[0].SHA != head {
+			fmt.Printf("expected HEAD to stay at %s, got %s\n", head, fake.History[0].SHA)
+			t.Fail()
+		}
+	})
+}
+
+func TestCheckRewritable(t *testing.T) {
+	t.Run("a linear, unprotected history", func(t *testing.T) {
+		_, f := rewordRepo(t, "protected_refs: [main]")
+		if err := checkRewritable(f.cfg, f.commits); err != nil {
+			fmt.Println(err)
+			t.Fail()
+		}
+	})
+	t.Run("a protected ref", func(t *testing.T) {
+		fake, f := rewordRepo(t, `protected_refs: [main, "origin/*"]`)
+		fake.Refs = map[string]string{"refs/heads/main": "a", "refs/remotes/origin/main": "b"}
+		err := checkRewritable(f.cfg, f.commits)
+		if err == nil || !strings.Contains(err.Error(), "protected ref origin/main") {
+			fmt.Printf("expected origin/main to protect b, got %v\n", err)
+			t.Fail()
+		}
+		if err := checkRewritable(f.cfg, f.commits[1:]); err != nil {
+			fmt.Printf("expected c to be rewritable, got %v\n", err)
+			t.Fail()
+		}
+	})
+	t.Run("a later merge", func(t *testing.T) {
+		fake, f := rewordRepo(t, "")
+		fake.History = append([]git.Commit{{SHA: "m", Message: "Merge branch 'x'", Parents: []string{"c", "x"}}}, fake.History...)
+		fake.History = append(fake.History, git.Commit{SHA: "x", Message: "feat: x", Parents: []string{"a"}})
+		commits, err := readCommits(fake, "a..HEAD")
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if err := checkRewritable(f.cfg, commits); err == nil || !strings.Contains(err.Error(), "merge") {
+			fmt.Printf("expected the merge to be refused, got %v\n", err)
+			t.Fail()
+		}
+	})
+}
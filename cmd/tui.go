@@ -9,7 +9,12 @@ import (
 	"github.com/skalt/git-cc/internal/breaking_change_input"
 	"github.com/skalt/git-cc/internal/config"
 	"github.com/skalt/git-cc/internal/description_editor"
+	"github.com/skalt/git-cc/internal/footer_input"
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/internal/i18n"
+	"github.com/skalt/git-cc/internal/lint"
 	"github.com/skalt/git-cc/internal/scope_selector"
+	"github.com/skalt/git-cc/internal/staged_panel"
 	"github.com/skalt/git-cc/internal/type_selector"
 	"github.com/skalt/git-cc/pkg/parser"
 )
@@ -21,6 +26,7 @@ const ( // the order of the components
 	scopeIndex
 	shortDescriptionIndex
 	breakingChangeIndex
+	requiredFootersIndex
 	// body omitted -- performed by GIT_EDITOR
 	nIndices // the number of indices
 )
@@ -34,6 +40,11 @@ var (
 		"no-gpg-sign",
 		"no-verify", // https://git-scm.com/docs/git-commit#Documentation/git-commit.txt---no-verify
 		"allow-empty",
+		"amend",
+	}
+	stringFlags = [...]string{
+		"author",
+		"date",
 	}
 )
 
@@ -50,19 +61,72 @@ type model struct {
 	scopeInput          scope_selector.Model
 	descriptionInput    description_editor.Model
 	breakingChangeInput breaking_change_input.Model
+	footersInput        footer_input.Model
 	// the width of the terminal; needed for instantiating components
 	// width  int
 	// any body stashed during the initial parse of command-line --message args
 	remainingBody string
+	// any footers other than breaking changes from the initial parse
+	footers []string
+	cfg     *config.Cfg
+	// rule violations found when the commit was last submitted
+	violations []lint.Violation
+	// shown above everything else, e.g. which commit is being revised
+	heading string
+	// a ticket from the branch name to prefix the description with, if
+	// ticket_header_format is set
+	ticket string
+	// the staged changes, listed once ctrl+s first shows them
+	stagedPanel   *staged_panel.Model
+	showingStaged bool
+	// the last known terminal size, for sizing the staged changes panel
+	windowSize tea.WindowSizeMsg
 }
 
 var _ tea.Model = model{}
 
 // returns whether the minimum requirements for a conventional commit are met.
 func (m model) ready() bool {
+	if m.firstInvalidComponent() < nIndices {
+		return false
+	}
 	return len(m.commit[commitTypeIndex]) > 0 && len(m.commit[shortDescriptionIndex]) > 0
 }
 
+// run the configured rules against the current value
+func (m model) lint() []lint.Violation {
+	value := m.value()
+	cc, _ := parser.ParseAsMuchOfCCAsPossible(value)
+	return lint.Lint(m.cfg, cc, value)
+}
+
+// the first component with an error-level rule violation, or nIndices if
+// there's nothing the TUI can fix.
+func (m model) firstInvalidComponent() componentIndex {
+	result := nIndices
+	for _, v := range m.violations {
+		if v.Level < config.RuleError {
+			continue
+		}
+		index := nIndices
+		switch v.Target() {
+		case "type":
+			index = commitTypeIndex
+		case "scope":
+			index = scopeIndex
+		case "subject", "header":
+			index = shortDescriptionIndex
+		}
+		if v.Rule == "footer-required" {
+			index = requiredFootersIndex
+		}
+		if index < result {
+			result = index
+		}
+	}
+	return result
+}
+
 // returns the context portion of the CC header, e.g `type(scope): `.
 func (m model) contextValue() string {
 	result := strings.Builder{}
@@ -78,6 +142,33 @@ func (m model) contextValue() string {
 	result.WriteString(": ")
 	return result.String()
 }
+
+// the reference to the ticket the description starts with, unless the message
+// already mentions it
+func (m model) ticketPrefix() string {
+	if m.ticket == "" || m.cfg.TicketHeaderFormat == "" {
+		return ""
+	}
+	for _, part := range append([]string{m.descriptionValue(), m.remainingBody}, m.footers...) {
+		if strings.Contains(part, m.ticket) {
+			return ""
+		}
+	}
+	return m.cfg.TicketHeader(m.ticket)
+}
+
+// everything in the header before the description the user types, which
+// counts towards header_max_length
+func (m model) headerPrefix() string {
+	return m.contextValue() + m.ticketPrefix()
+}
+
+// prefix the description with a ticket as ticket_header_format says
+func (m model) withTicket(ticket string) model {
+	m.ticket = ticket
+	m.descriptionInput = m.descriptionInput.SetPrefix(m.headerPrefix())
+	return m
+}
 func (m model) descriptionValue() string {
 	return m.commit[shortDescriptionIndex]
 }
@@ -88,16 +179,27 @@ func (m model) breakingChangeValue() string {
 // Returns a pretty-printed CC string. The model should be `.ready()` before you call `.value()`.
 func (m model) value() string {
 	result := strings.Builder{}
-	result.WriteString(m.contextValue())
+	result.WriteString(m.headerPrefix())
 	result.WriteString(m.descriptionValue())
 	result.WriteString("\n")
 	if m.remainingBody != "" {
+		result.WriteString("\n")
 		result.WriteString(m.remainingBody)
 		result.WriteString("\n")
 	}
-	if breakingChange := m.breakingChangeValue(); breakingChange != "" {
+	footers := []string{}
+	if breakingChange := strings.TrimSpace(m.breakingChangeValue()); breakingChange != "" {
 		// TODO: handle multiple breaking change footers(?)
-		result.WriteString(fmt.Sprintf("\n\nBREAKING CHANGE: %s\n", breakingChange))
+		footers = append(footers, "BREAKING CHANGE: "+breakingChange)
+	}
+	footers = append(footers, m.footers...)
+	if required := m.commit[requiredFootersIndex]; required != "" {
+		footers = append(footers, strings.Split(required, "\n")...)
+	}
+	if len(footers) > 0 {
+		result.WriteString("\n")
+		result.WriteString(strings.Join(footers, "\n"))
+		result.WriteString("\n")
 	}
 	return result.String()
 }
@@ -112,33 +214,36 @@ func (m model) currentComponent() InputComponent {
 		m.scopeInput,
 		m.descriptionInput,
 		m.breakingChangeInput,
+		m.footersInput,
 	}[m.viewing]
 }
 
-// Pass a channel to the model to listen to the result value. This is a
-// function that returns the initialize function and is typically how you would
-// pass arguments to a tea.Init function.
-func initialModel(cc *parser.CC, cfg *config.Cfg) model {
+// a model prefilled with the commit, viewing the type selector. Breaking-change
+// footers go to the breaking-change input; other footers are kept as-is.
+func newModel(cc *parser.CC, cfg *config.Cfg) model {
 	typeModel := type_selector.NewModel(cc, cfg)
 	scopeModel := scope_selector.NewModel(cc, *cfg)
 	descModel := description_editor.NewModel(
 		cfg.HeaderMaxLength, cc.Description, cfg.EnforceMaxLength,
 	)
-	bcModel := breaking_change_input.NewModel()
 	breakingChanges := ""
-	if cc.BreakingChange {
-		for _, footer := range cc.Footers {
-			result, err := parser.BreakingChange([]rune(footer))
-			if err == nil {
-				breakingChanges += string(result.Remaining) + "\n"
-			}
+	footers := []string{}
+	for _, footer := range cc.Footers {
+		result, err := parser.BreakingChange([]rune(footer))
+		if err == nil {
+			breakingChanges += strings.TrimLeft(string(result.Remaining), ": ") + "\n"
+		} else {
+			footers = append(footers, footer)
 		}
 	}
+	breakingChanges = strings.TrimSpace(breakingChanges)
+	bcModel := breaking_change_input.NewModel(breakingChanges)
 	commit := [nIndices]string{
 		cc.Type,
 		cc.Scope,
 		cc.Description,
 		breakingChanges,
+		"",
 	}
 	m := model{
 		commit:              commit,
@@ -146,16 +251,65 @@ func initialModel(cc *parser.CC, cfg *config.Cfg) model {
 		scopeInput:          scopeModel,
 		descriptionInput:    descModel,
 		breakingChangeInput: bcModel,
+		footersInput:        footer_input.NewModel(),
 		viewing:             commitTypeIndex,
 		remainingBody:       cc.Body,
+		footers:             footers,
+		cfg:                 cfg,
 	}
+	return m
+}
+
+// a model for completing a commit, skipping a valid type and scope
+func initialModel(cc *parser.CC, cfg *config.Cfg) model {
+	m := newModel(cc, cfg)
 	if m.shouldSkip(m.viewing) {
 		m = m.submit().advance()
-		m.descriptionInput = m.descriptionInput.SetPrefix(m.contextValue())
+		m.descriptionInput = m.descriptionInput.SetPrefix(m.headerPrefix())
+	}
+	return m
+}
+
+// a model for revising an existing commit message: it opens at the first
+// component that needs fixing, or at the type selector if nothing does.
+func revisionModel(cc *parser.CC, cfg *config.Cfg) model {
+	m := newModel(cc, cfg)
+	m.descriptionInput = m.descriptionInput.SetPrefix(m.headerPrefix())
+	m.violations = m.lint()
+	if invalid := m.firstInvalidComponent(); invalid < nIndices {
+		m.viewing = invalid
+	}
+	if m.viewing == requiredFootersIndex {
+		m.footersInput = m.footersInput.Require(m.missingFooters())
 	}
 	return m
 }
 
+// page through the staged changes to a file, pausing the TUI meanwhile
+func (m model) pageStagedDiff(file git.FileStat) tea.Cmd {
+	paths := []string{file.Path}
+	if file.From != "" {
+		paths = append(paths, file.From)
+	}
+	return tea.Exec(m.cfg.Repo.PageStagedDiff(paths...), func(error) tea.Msg { return nil })
+}
+
+// show or hide the staged changes, listing them the first time they're shown
+func (m model) toggleStaged() (model, tea.Cmd) {
+	m.showingStaged = !m.showingStaged
+	if m.stagedPanel != nil || !m.showingStaged {
+		return m, nil
+	}
+	files, err := m.cfg.Repo.StagedStats()
+	panel := staged_panel.NewModel(files, err, m.pageStagedDiff)
+	var cmd tea.Cmd
+	if m.windowSize.Width > 0 {
+		panel, cmd = panel.Update(m.windowSize)
+	}
+	m.stagedPanel = &panel
+	return m, cmd
+}
+
 // pass the `msg` to the currently-displayed component/view
 func (m model) updateCurrentInput(msg tea.Msg) (model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -168,6 +322,8 @@ func (m model) updateCurrentInput(msg tea.Msg) (model, tea.Cmd) {
 		m.descriptionInput, cmd = m.descriptionInput.Update(msg)
 	case breakingChangeIndex:
 		m.breakingChangeInput, cmd = m.breakingChangeInput.Update(msg)
+	case requiredFootersIndex:
+		m.footersInput, cmd = m.footersInput.Update(msg)
 	}
 	return m, cmd
 }
@@ -178,24 +334,61 @@ func (m model) shouldSkip(component componentIndex) bool {
 		return m.typeInput.ShouldSkip(m.commit[commitTypeIndex])
 	case scopeIndex:
 		return m.scopeInput.ShouldSkip(m.commit[scopeIndex])
+	case requiredFootersIndex:
+		return len(m.missingFooters()) == 0
 	default:
 		return false
 	}
 }
 
+// the required footers the commit's type calls for that aren't already present
+func (m model) missingFooters() []config.RequiredFooter {
+	return m.cfg.MissingFooters(m.commit[commitTypeIndex], m.footers)
+}
+
+// move to the next component that needs input, or past the last one.
 func (m model) advance() model { // TODO: consider submitting w/in this fn
 	for {
 		m.viewing++
-		if !m.shouldSkip(m.viewing) {
+		if m.viewing >= nIndices || !m.shouldSkip(m.viewing) {
 			break
 		}
+		if m.viewing == requiredFootersIndex {
+			m.commit[requiredFootersIndex] = "" // e.g. the type changed
+		}
+	}
+	if m.viewing == requiredFootersIndex {
+		m.footersInput = m.footersInput.Require(m.missingFooters())
 	}
 	return m
 }
 
+// lint the commit, then either quit or return to the first component that
+// needs fixing.
+func (m model) finish() (model, tea.Cmd) {
+	m.violations = m.lint()
+	if m.ready() {
+		m.viewing = breakingChangeIndex // leave a component to render
+		return m, tea.Quit
+	}
+	if invalid := m.firstInvalidComponent(); invalid < nIndices {
+		m.viewing = invalid
+	} else if m.commit[commitTypeIndex] == "" {
+		m.viewing = commitTypeIndex
+	} else if m.commit[shortDescriptionIndex] == "" {
+		m.viewing = shortDescriptionIndex
+	} else {
+		m.viewing = breakingChangeIndex
+	}
+	if m.viewing == requiredFootersIndex {
+		m.footersInput = m.footersInput.Require(m.missingFooters())
+	}
+	return m, nil
+}
+
 func (m model) submit() model {
 	m.commit[m.viewing] = m.currentComponent().Value()
-	m.descriptionInput = m.descriptionInput.SetPrefix(m.contextValue())
+	m.descriptionInput = m.descriptionInput.SetPrefix(m.headerPrefix())
 	return m
 }
 
@@ -206,6 +399,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "ctrl+c", "ctrl+d":
 			return m, tea.Quit
+		case "ctrl+s":
+			return m.toggleStaged()
+		}
+		if m.showingStaged {
+			if msg.Code == tea.KeyEscape {
+				return m.toggleStaged()
+			}
+			if m.stagedPanel.Captures(msg) {
+				panel, cmd := m.stagedPanel.Update(msg)
+				m.stagedPanel = &panel
+				return m, cmd
+			}
 		}
 		switch msg.Code {
 		case tea.KeyEnter, tea.KeyTab:
@@ -218,6 +423,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch m.viewing {
 			default:
 				m = m.submit().advance()
+			case requiredFootersIndex:
+				m.footersInput, cmd = m.footersInput.Update(msg)
+				if !m.footersInput.Done() {
+					return m, cmd
+				}
+				m = m.submit().advance()
 			case commitTypeIndex:
 				if m.currentComponent().Value() == "" {
 					return m, cmd
@@ -231,19 +442,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m = m.submit().advance()
 				}
-			case breakingChangeIndex:
-				m = m.submit()
-				if m.ready() {
-					return m, tea.Quit
-				} else {
-					// TODO: better validation messages
-					if m.commit[commitTypeIndex] == "" {
-						m.viewing = commitTypeIndex
-					} else if m.commit[shortDescriptionIndex] == "" {
-						m.viewing = shortDescriptionIndex
-					}
-					return m, cmd
-				}
+			}
+			if m.viewing >= nIndices {
+				return m.finish()
 			}
 			return m, cmd
 		default:
@@ -254,7 +455,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.typeInput, _ = m.typeInput.Update(msg)
 		m.scopeInput, _ = m.scopeInput.Update(msg)
 		m.descriptionInput, _ = m.descriptionInput.Update(msg)
-		m.breakingChangeInput, cmd = m.breakingChangeInput.Update(msg)
+		m.breakingChangeInput, _ = m.breakingChangeInput.Update(msg)
+		m.footersInput, cmd = m.footersInput.Update(msg)
+		m.windowSize = msg
+		if m.stagedPanel != nil {
+			panel, _ := m.stagedPanel.Update(msg)
+			m.stagedPanel = &panel
+		}
 	default:
 		m, cmd = m.updateCurrentInput(msg)
 	}
@@ -264,8 +471,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m model) View() (v tea.View) {
 	v.AltScreen = true
 	s := strings.Builder{}
+	if m.heading != "" {
+		s.WriteString(m.heading)
+		s.WriteString("\n")
+	}
+	for _, violation := range m.violations {
+		s.WriteString(config.Faint(violation.String()))
+		s.WriteString("\n")
+	}
 	m.currentComponent().Render(&s)
 	s.WriteString("\n")
+	if m.showingStaged {
+		m.stagedPanel.Render(&s)
+	} else {
+		s.WriteString(config.Faint(i18n.T("help.staged")))
+		s.WriteString("\n")
+	}
 	v.Content = s.String()
 	return v
 }
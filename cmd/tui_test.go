@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// send keys to a model as if they were typed. "enter", "down", and "ctrl+s" are
+// pressed; anything else is typed out.
+func typeKeys(m model, keys ...string) model {
+	for _, key := range keys {
+		msgs := []tea.KeyPressMsg{}
+		switch key {
+		case "enter":
+			msgs = append(msgs, tea.KeyPressMsg{Code: tea.KeyEnter})
+		case "down":
+			msgs = append(msgs, tea.KeyPressMsg{Code: tea.KeyDown})
+		case "ctrl+s":
+			msgs = append(msgs, tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+		default:
+			for _, r := range key {
+				msgs = append(msgs, tea.KeyPressMsg{Code: r, Text: string(r)})
+			}
+		}
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	return m
+}
+
+func TestModel(t *testing.T) {
+	t.Run("revising HEAD starts at a missing type", func(t *testing.T) {
+		fake, cfg := fakeRepo(t, "")
+		fake.History = []git.Commit{{SHA: "abc", Message: "Handle tabs\n\nbody"}}
+		head, err := fake.HeadMessage()
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		m := revisionModel(parseRevision(head), cfg)
+		if m.viewing != commitTypeIndex {
+			fmt.Printf("expected to start at the type, got %d\n", m.viewing)
+			t.FailNow()
+		}
+		m = typeKeys(m, "fix", "enter", "enter", "enter", "enter")
+		if !m.ready() {
+			fmt.Printf("expected the commit to be ready, viewing %d\n", m.viewing)
+			t.FailNow()
+		}
+		if expected := "fix: Handle tabs\n\nbody"; strings.TrimSpace(m.value()) != expected {
+			fmt.Printf("expected %q, got %q\n", expected, m.value())
+			t.Fail()
+		}
+	})
+	t.Run("a valid type is skipped", func(t *testing.T) {
+		_, cfg := fakeRepo(t, "")
+		m := initialModel(&parser.CC{Type: "feat"}, cfg)
+		if m.viewing != scopeIndex {
+			fmt.Printf("expected to start at the scope, got %d\n", m.viewing)
+			t.FailNow()
+		}
+		m = typeKeys(m, "enter", "add x", "enter", "enter")
+		if !m.ready() {
+			fmt.Printf("expected the commit to be ready, viewing %d\n", m.viewing)
+			t.FailNow()
+		}
+		if strings.TrimSpace(m.value()) != "feat: add x" {
+			fmt.Printf("expected %q, got %q\n", "feat: add x", m.value())
+			t.Fail()
+		}
+	})
+	t.Run("ctrl+s shows the staged changes", func(t *testing.T) {
+		fake, cfg := fakeRepo(t, "")
+		fake.Stats = []git.FileStat{
+			{Path: "README.md", Added: 3, Deleted: 1},
+			{Path: "logo.png", Binary: true},
+		}
+		m := typeKeys(initialModel(&parser.CC{}, cfg), "ctrl+s")
+		view := m.View().Content
+		for _, expected := range []string{"README.md | 4 +++-", "logo.png  | Bin"} {
+			if !strings.Contains(view, expected) {
+				fmt.Printf("expected %q in the view:\n%s\n", expected, view)
+				t.Fail()
+			}
+		}
+		// the panel takes navigation keys, while typing still reaches the prompt
+		m = typeKeys(m, "down", "fi")
+		if file, _ := m.stagedPanel.Selected(); file.Path != "logo.png" {
+			fmt.Printf("expected logo.png to be selected, got %q\n", file.Path)
+			t.Fail()
+		}
+		if _, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd == nil {
+			fmt.Println("expected enter to page through the selected file's diff")
+			t.Fail()
+		}
+		if len(fake.Paged) != 1 || !slices.Equal(fake.Paged[0], []string{"logo.png"}) {
+			fmt.Printf("expected to page through logo.png's diff, got %q\n", fake.Paged)
+			t.Fail()
+		}
+		m = typeKeys(m, "ctrl+s", "enter")
+		if m.commit[commitTypeIndex] != "fix" {
+			fmt.Printf("expected the type to be submitted once the panel's hidden, got %q\n", m.commit[commitTypeIndex])
+			t.Fail()
+		}
+	})
+}
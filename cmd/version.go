@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/pkg/parser"
+	"github.com/skalt/git-cc/pkg/semver"
+)
+
+// the first release of a repo or package without any version tags. Bumping
+// 0.0.0 would make a feature's first release 0.0.1.
+var firstVersion = semver.Version{Minor: 1}
+
+type taggedVersion struct {
+	Tag     string
+	Version semver.Version
+}
+
+// the tags reachable from a ref that are a prefix followed by a semantic
+// version, e.g. `v1.2.3`
+func semverTags(repo git.Repo, ref string, prefix string) ([]taggedVersion, error) {
+	tags, err := repo.Tags(ref, prefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	result := []taggedVersion{}
+	for _, tag := range tags {
+		if v, err := semver.Parse(strings.TrimPrefix(tag, prefix)); err == nil {
+			result = append(result, taggedVersion{tag, v})
+		}
+	}
+	return result, nil
+}
+
+// how much a commit moves the version: breaking changes are major, and
+// otherwise it's up to the commit type's `bump` detail.
+func bumpOf(cfg *config.Cfg, message string) semver.Level {
+	cc, err := parser.ParseAsMuchOfCCAsPossible(message)
+	if err != nil || cc.Type == "" {
+		return semver.None // not a conventional commit
+	}
+	if cc.BreakingChange {
+		return semver.Major
+	}
+	level, err := semver.ParseLevel(cfg.TypeInfoOf(cfg.CanonicalType(cc.Type)).Bump)
+	if err != nil {
+		return semver.None
+	}
+	return level
+}
+
+// the tag and version of the next release of `ref`, based on the commits to
+// the package since its latest release. Pre-releases are numbered after any
+// earlier pre-releases of the same version, e.g. v1.3.0-rc.0, then v1.3.0-rc.1.
+func nextVersion(cfg *config.Cfg, pkg *monorepoPackage, ref string, prefix string, pre string) (string, semver.Version, error) {
+	tags, err := semverTags(cfg.Repo, ref, prefix)
+	if err != nil {
+		return "", semver.Version{}, err
+	}
+	var latest *taggedVersion
+	for i, t := range tags {
+		if !t.Version.IsPrerelease() && (latest == nil || semver.Compare(t.Version, latest.Version) > 0) {
+			latest = &tags[i]
+		}
+	}
+	revRange, base := ref, semver.Version{}
+	if latest != nil {
+		revRange, base = latest.Tag+".."+ref, latest.Version
+	}
+	commits, err := pkg.commits(cfg, revRange)
+	if err != nil {
+		return "", base, err
+	}
+	level := semver.None
+	for _, c := range commits {
+		level = max(level, bumpOf(cfg, c.Message))
+	}
+	if level == semver.None {
+		since := "the first commit"
+		if latest != nil {
+			since = latest.Tag
+		}
+		return "", base, fmt.Errorf("no commits since %s call for a new version", since)
+	}
+	next := firstVersion
+	if latest != nil {
+		next = base.Bump(level)
+	}
+	if pre != "" {
+		number := 0
+		for _, t := range tags {
+			v := t.Version
+			if v.Major != next.Major || v.Minor != next.Minor || v.Patch != next.Patch || len(v.Pre) != 2 || v.Pre[0] != pre {
+				continue
+			}
+			if n, err := strconv.Atoi(v.Pre[1]); err == nil && n >= number {
+				number = n + 1
+			}
+		}
+		next.Pre = []string{pre, strconv.Itoa(number)}
+	}
+	return prefix + next.String(), next, nil
+}
+
+func runVersionNext(cmd *cobra.Command, args []string) {
+	flags := cmd.Flags()
+	tag, _ := flags.GetBool("tag")
+	pre, _ := flags.GetString("pre")
+	prefix, _ := flags.GetString("tag-prefix")
+	to, _ := flags.GetString("to")
+	pkg := packageFromFlags(cmd)
+	if !flags.Changed("tag-prefix") {
+		prefix = pkg.tagPrefix()
+	}
+	if pre != "" {
+		if _, err := semver.Parse("0.0.0-" + pre); err != nil || strings.Contains(pre, ".") {
+			log.Fatalf("invalid pre-release identifier %q", pre)
+		}
+	}
+	cfg, err := config.Init(true)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	next, version, err := nextVersion(cfg, pkg, to, prefix, pre)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	if problem := goMajorVersionProblem(pkg.dir(cfg), version); problem != "" {
+		if tag {
+			log.Fatalf("unable to tag %s: %s", next, problem)
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s\n", problem)
+	}
+	if tag {
+		if err := cfg.Repo.CreateTag(next, to, next); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+	fmt.Println(next)
+}
+
+func versionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "compute versions from conventional commits",
+	}
+	next := &cobra.Command{
+		Use:   "next",
+		Short: "print the next semantic version based on the commits since the latest release",
+		Long: `Print the tag of the next release: the latest semantic-version tag reachable
+from --to, bumped by the commits since then. Breaking changes bump the major
+version, and other commits bump the version as their type's "bump" detail says:
+by default, feat bumps the minor version and fix and perf bump the patch
+version. Before 1.0.0, breaking changes only bump the minor version and
+features only the patch version. Without any version tags, the first release
+is 0.1.0.
+
+In a monorepo, --package versions a package on its own, using the commits that
+change files in its directory and tags like DIR/v1.2.3. If the package is a Go
+module, a new major version from v2 on needs a module path ending in /vN.`,
+		Example: `  git cc version next            # e.g. v1.3.0
+  git cc version next --pre rc   # e.g. v1.3.0-rc.0, then v1.3.0-rc.1
+  git cc version next --tag      # also create an annotated tag
+  git cc version next --package pkg/parser  # e.g. pkg/parser/v1.4.0`,
+		Args: cobra.NoArgs,
+		Run:  runVersionNext,
+	}
+	flags := next.Flags()
+	flags.Bool("tag", false, "create an annotated tag for the next version")
+	flags.String("pre", "", "make a pre-release with this identifier, e.g. rc or beta")
+	flags.String("tag-prefix", "v", "the prefix of version tags; defaults to DIR/v with --package DIR")
+	flags.String("to", "HEAD", "the commit to version")
+	addPackageFlags(flags)
+	cmd.AddCommand(next)
+	return cmd
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+func TestNextVersion(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		tags     map[string]string
+		message  string
+		pre      string
+		expected string
+	}{
+		{"untagged fix", nil, "fix: b", "", "v0.1.0"},
+		{"untagged feature", nil, "feat: b", "", "v0.1.0"},
+		{"untagged breaking change", nil, "feat!: b", "", "v0.1.0"},
+		{"untagged pre-release", nil, "feat: b", "rc", "v0.1.0-rc.0"},
+		{"0.x feature", map[string]string{"v0.1.0": "a"}, "feat: b", "", "v0.1.1"},
+		{"0.x breaking change", map[string]string{"v0.1.0": "a"}, "fix!: b", "", "v0.2.0"},
+		{"fix", map[string]string{"v1.2.3": "a"}, "fix: b", "", "v1.2.4"},
+		{"feature", map[string]string{"v1.2.3": "a"}, "feat: b", "", "v1.3.0"},
+		{"breaking change", map[string]string{"v1.2.3": "a"}, "feat!: b", "", "v2.0.0"},
+		{"next pre-release", map[string]string{"v1.2.3": "a", "v1.3.0-rc.0": "a"}, "feat: b", "rc", "v1.3.0-rc.1"},
+		{"nothing to release", map[string]string{"v1.2.3": "a"}, "chore: b", "", ""},
+		{"nothing to release untagged", nil, "docs: b", "", ""},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			fake, cfg := fakeRepo(t, "")
+			fake.History = []git.Commit{{SHA: "b", Message: c.message}, {SHA: "a", Message: "chore: a"}}
+			fake.Refs = map[string]string{}
+			for tag, sha := range c.tags {
+				fake.Refs["refs/tags/"+tag] = sha
+			}
+			next, _, err := nextVersion(cfg, nil, "HEAD", "v", c.pre)
+			if next != c.expected || (err == nil) != (c.expected != "") {
+				fmt.Printf("expected %q, got %q and %v\n", c.expected, next, err)
+				t.Fail()
+			}
+		})
+	}
+}
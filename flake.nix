@@ -35,12 +35,10 @@
             libfaketime
             glibc
             git
-            nodejs
             gomod2nix.packages.${system}.default
             coreutils-full
             toybox
             vim
-            nodePackages.pnpm
             goreleaser
             ttyd
             ffmpeg
@@ -2,13 +2,12 @@ package breaking_change_input
 
 import (
 	"io"
-	"strings"
 
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"github.com/muesli/termenv"
-	"github.com/skalt/git-cc/internal/config"
 	"github.com/skalt/git-cc/internal/helpbar"
+	"github.com/skalt/git-cc/internal/i18n"
 	"github.com/skalt/git-cc/internal/utils"
 )
 
@@ -17,10 +16,6 @@ type Model struct {
 	helpBar helpbar.Model
 }
 
-var helpBar = termenv.String(strings.Join(
-	[]string{config.HelpSubmit, config.HelpBack, config.HelpCancel}, "; "),
-).Faint().String()
-
 func (m Model) Value() string {
 	return m.input.Value()
 }
@@ -28,23 +23,27 @@ func (m Model) Value() string {
 func (m Model) Render(b io.StringWriter) {
 	_ = utils.Must(b.WriteString(m.input.View()))
 	_ = utils.Must(b.WriteString("\n\n"))
-	_ = utils.Must(b.WriteString(helpBar))
+	m.helpBar.Render(b)
 	_ = utils.Must(b.WriteString("\n"))
 }
 
 func (m Model) Update(msg tea.Msg) (out Model, cmd tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.helpBar, _ = m.helpBar.Update(msg)
+	}
 	m.input, cmd = m.input.Update(msg)
 	out = m
 	return
 }
 
-func NewModel() Model {
+func NewModel(value string) Model {
 	input := textinput.New()
-	input.Prompt = termenv.String("Breaking changes: ").Faint().String()
-	input.Placeholder = "if any."
+	input.Prompt = termenv.String(i18n.T("prompt.breaking_change")).Faint().String()
+	input.Placeholder = i18n.T("placeholder.breaking_change")
+	input.SetValue(value)
 	input.Focus()
 	return Model{
 		input,
-		helpbar.NewModel(config.HelpSubmit, config.HelpBack, config.HelpCancel),
+		helpbar.NewModel(i18n.T("help.submit"), i18n.T("help.back"), i18n.T("help.cancel")),
 	}
 }
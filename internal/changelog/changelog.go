@@ -0,0 +1,126 @@
+package changelog
+
+// render markdown changelogs from conventional commits, in the style of
+// conventional-changelog's angular preset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skalt/git-cc/internal/config"
+)
+
+const Unreleased = "Unreleased"
+
+type Commit struct {
+	Hash    string
+	Message string
+	Author  string
+	Email   string
+}
+
+// the commits between two releases
+type Release struct {
+	// the heading of the release, e.g. "v1.2.0" or "Unreleased"
+	Name string
+	// the ref to compare against Previous, e.g. "v1.2.0" or "HEAD"
+	Ref string
+	// the tag of the previous release; "" for the first release
+	Previous string
+	// YYYY-MM-DD
+	Date    string
+	Commits []Commit
+}
+
+// templates of the links in a changelog, using conventional-changelog's
+// placeholders. Empty templates leave things unlinked.
+type Links struct {
+	// e.g. "https://github.com/o/r/commit/{{hash}}"
+	Commit string
+	// e.g. "https://github.com/o/r/issues/{{id}}"
+	Issue string
+	// e.g. "https://github.com/o/r/compare/{{previousTag}}...{{currentTag}}"
+	Compare string
+	// whether to only link GitHub-style issue references like #12, e.g. since
+	// the issue template was guessed rather than configured
+	NumberedIssuesOnly bool
+}
+
+// footers that close issues, following GitHub's keywords; see
+// https://docs.github.com/en/issues/tracking-your-work-with-issues/linking-a-pull-request-to-an-issue
+var closingTokens = []string{"close", "closes", "closed", "fix", "fixes", "fixed", "resolve", "resolves", "resolved"}
+
+// the issues or tickets a footer's value lists, e.g. "#12, #13" or "PAY-123"
+func references(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// a section of breaking changes, then one section per changelog section of
+// the configured types, in the order the types are configured. Hidden types
+// are only listed if they break something.
+func Render(cfg *config.Cfg, links Links, release Release) string {
+	tmpl, err := BuiltinTemplate("markdown")
+	if err != nil {
+		panic(err) // the built-in templates are known to parse
+	}
+	s := strings.Builder{}
+	if err := tmpl.Execute(&s, Collect(cfg, links, release)); err != nil {
+		panic(err)
+	}
+	return s.String()
+}
+
+// the release a markdown heading like `## [v1.2.0](...) (2006-01-02)` or
+// `### 1.2.0` names, if any. Headings of sections, like `### Features`, don't
+// name releases.
+func headingName(line string) (string, bool) {
+	if !strings.HasPrefix(line, "## ") && !strings.HasPrefix(line, "### ") {
+		return "", false
+	}
+	text := strings.TrimSpace(strings.TrimLeft(line, "#"))
+	if strings.HasPrefix(text, "[") {
+		if end := strings.Index(text, "]"); end > 0 {
+			return text[1:end], true
+		}
+	}
+	name, _, _ := strings.Cut(text, " ")
+	version := strings.TrimPrefix(name, "v")
+	if name == Unreleased || (version != "" && version[0] >= '0' && version[0] <= '9') {
+		return name, true
+	}
+	return "", false
+}
+
+// insert a release's section above the newest release in an existing
+// changelog, leaving everything else as it was.
+func Prepend(existing string, section string, name string) (string, error) {
+	if strings.TrimSpace(existing) == "" {
+		return "# Changelog\n\n" + section, nil
+	}
+	lines := strings.SplitAfter(existing, "\n")
+	insertAt := len(lines)
+	for i, line := range lines {
+		if other, ok := headingName(strings.TrimRight(line, "\r\n")); ok {
+			if strings.TrimPrefix(other, "v") == strings.TrimPrefix(name, "v") {
+				return "", fmt.Errorf("the changelog already has a section for %s", name)
+			}
+			if i < insertAt {
+				insertAt = i
+			}
+		}
+	}
+	before := strings.Join(lines[:insertAt], "")
+	after := strings.Join(lines[insertAt:], "")
+	if !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+	if !strings.HasSuffix(before, "\n\n") {
+		before += "\n"
+	}
+	if after != "" {
+		section += "\n"
+	}
+	return before + section + after, nil
+}
@@ -0,0 +1,179 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/config"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func testCfg() *config.Cfg {
+	types := orderedmap.New[string, string]()
+	types.Set("feat", "adds a new feature")
+	types.Set("fix", "fixes a bug")
+	types.Set("docs", "changes only the documentation")
+	scopes := orderedmap.New[string, string]()
+	scopes.Set("cmd", "the cli")
+	return &config.Cfg{
+		CommitTypes:  types,
+		Scopes:       scopes,
+		TypeInfo:     map[string]config.CommitTypeInfo{},
+		ScopeInfo:    map[string]config.ScopeInfo{"cmd": {Aliases: []string{"cli"}}},
+		TicketFooter: "Refs",
+	}
+}
+
+func TestRender(t *testing.T) {
+	links := Links{
+		Commit:             "https://example.com/commit/{{hash}}",
+		Issue:              "https://example.com/issues/{{id}}",
+		Compare:            "https://example.com/compare/{{previousTag}}...{{currentTag}}",
+		NumberedIssuesOnly: true,
+	}
+	release := Release{
+		Name: "v1.1.0", Ref: "v1.1.0", Previous: "v1.0.0", Date: "2020-01-02",
+		Commits: []Commit{
+			{Hash: "1111111111", Message: "fix: handle nulls\n\nCloses #12"},
+			{Hash: "2222222222", Message: "feat(cli)!: drop --foo\n\nBREAKING CHANGE: --foo is gone\nRefs: PAY-9"},
+			{Hash: "3333333333", Message: "docs: fix a typo"},
+			{Hash: "4444444444", Message: "feat: add refunds"},
+			{Hash: "5555555555", Message: "Merge branch 'main'"},
+		},
+	}
+	expected := `## [v1.1.0](https://example.com/compare/v1.0.0...v1.1.0) (2020-01-02)
+
+### ⚠ BREAKING CHANGES
+
+* **cmd:** --foo is gone ([2222222](https://example.com/commit/2222222222))
+
+### Features
+
+* add refunds ([4444444](https://example.com/commit/4444444444))
+* **cmd:** drop --foo ([2222222](https://example.com/commit/2222222222)), refs PAY-9
+
+### Bug Fixes
+
+* handle nulls ([1111111](https://example.com/commit/1111111111)), closes [#12](https://example.com/issues/12)
+`
+	actual := Render(testCfg(), links, release)
+	if actual != expected {
+		fmt.Printf("expected:\n%s\ngot:\n%s\n", expected, actual)
+		t.Fail()
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	section := "## v1.1.0 (2020-01-02)\n\n### Features\n\n* new\n"
+	test := func(existing string, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			actual, err := Prepend(existing, section, "v1.1.0")
+			if err != nil {
+				fmt.Printf("%+v\n", err)
+				t.FailNow()
+			}
+			if actual != expected {
+				fmt.Printf("expected:\n%q\ngot:\n%q\n", expected, actual)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("into a new changelog", test("", "# Changelog\n\n"+section))
+	t.Run("above the newest release", test(
+		"# Changelog\n\nintro\n\n### [1.0.0](x) (2020-01-01)\n\n### Features\n\n* old\n",
+		"# Changelog\n\nintro\n\n"+section+"\n### [1.0.0](x) (2020-01-01)\n\n### Features\n\n* old\n",
+	))
+	t.Run("below the intro of a changelog without releases", test(
+		"# Changelog\n\nintro",
+		"# Changelog\n\nintro\n\n"+section,
+	))
+	t.Run("refusing to list a release twice", func(t *testing.T) {
+		if _, err := Prepend("# Changelog\n\n## [1.1.0](x)\n", section, "v1.1.0"); err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestCollect(t *testing.T) {
+	release := Release{
+		Name: "v1.1.0",
+		Commits: []Commit{
+			{Hash: "1111111111", Message: "fix(cli): handle nulls\n\nCloses #12", Author: "Ann", Email: "ann@example.com"},
+			{Hash: "2222222222", Message: "docs!: drop the old guide", Author: "Bo", Email: "bo@example.com"},
+			{Hash: "3333333333", Message: "Merge branch 'main'", Author: "Ann", Email: "ANN@example.com"},
+			{Hash: "4444444444", Message: "fix: handle zeroes\n\nRefs: PAY-9, #12", Author: "Bo", Email: "bo@example.com"},
+		},
+	}
+	notes := Collect(testCfg(), Links{}, release)
+	t.Run("authors", func(t *testing.T) {
+		expected := []Author{{"Ann", "ann@example.com", 2}, {"Bo", "bo@example.com", 2}}
+		if fmt.Sprint(notes.Authors) != fmt.Sprint(expected) {
+			fmt.Printf("expected %v, got %v\n", expected, notes.Authors)
+			t.Fail()
+		}
+	})
+	t.Run("references", func(t *testing.T) {
+		if fmt.Sprint(notes.References) != "[{#12 } {PAY-9 }]" {
+			fmt.Printf("unexpected references %v\n", notes.References)
+			t.Fail()
+		}
+	})
+	t.Run("breaking changes of hidden types", func(t *testing.T) {
+		if len(notes.Breaking) != 1 || notes.Breaking[0].Type != "docs" || len(notes.Groups) != 1 {
+			fmt.Printf("unexpected breaking changes %v and groups %v\n", notes.Breaking, notes.Groups)
+			t.Fail()
+		}
+	})
+	t.Run("grouping by scope", func(t *testing.T) {
+		scopes := notes.Groups[0].Scopes
+		if len(scopes) != 2 || scopes[0].Scope != "" || scopes[1].Scope != "cmd" {
+			fmt.Printf("unexpected scopes %v\n", scopes)
+			t.Fail()
+		}
+	})
+	t.Run("other commits", func(t *testing.T) {
+		if len(notes.Other) != 1 || notes.Other[0].Hash != "3333333333" {
+			fmt.Printf("unexpected other commits %v\n", notes.Other)
+			t.Fail()
+		}
+	})
+}
+
+func TestKeepAChangelog(t *testing.T) {
+	release := Release{
+		Name: "v1.1.0", Ref: "v1.1.0", Previous: "v1.0.0", Date: "2020-01-02",
+		Commits: []Commit{
+			{Hash: "1111111111", Message: "fix: handle nulls"},
+			{Hash: "2222222222", Message: "feat(cli)!: drop --foo"},
+			{Hash: "3333333333", Message: "docs: fix a typo"},
+		},
+	}
+	expected := `## [v1.1.0] - 2020-01-02
+
+### Added
+
+- **BREAKING:** **cmd:** drop --foo (2222222)
+
+### Fixed
+
+- handle nulls (1111111)
+
+[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0
+`
+	tmpl, err := BuiltinTemplate("keep-a-changelog")
+	if err != nil {
+		fmt.Printf("%+v\n", err)
+		t.FailNow()
+	}
+	actual := strings.Builder{}
+	links := Links{Compare: "https://example.com/compare/{{previousTag}}...{{currentTag}}"}
+	if err := tmpl.Execute(&actual, Collect(testCfg(), links, release)); err != nil {
+		fmt.Printf("%+v\n", err)
+		t.FailNow()
+	}
+	if actual.String() != expected {
+		fmt.Printf("expected:\n%s\ngot:\n%s\n", expected, actual.String())
+		t.Fail()
+	}
+}
@@ -0,0 +1,324 @@
+package changelog
+
+// structured release notes, for rendering through text/template
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// the names of the built-in templates
+var Formats = [...]string{"markdown", "keep-a-changelog"}
+
+type Author struct {
+	Name  string
+	Email string
+	// how many of the release's commits they authored
+	Commits int
+}
+
+// an issue or ticket a commit closes or refers to, e.g. "#12" or "PAY-123"
+type Reference struct {
+	ID string
+	// "" if there's no link for the reference
+	URL string
+}
+
+// a conventional commit, as release notes present it
+type Change struct {
+	Hash      string
+	ShortHash string
+	// "" if there's no link for the commit
+	URL string
+	// the canonical type and scope, resolving any aliases
+	Type  string
+	Scope string
+	// for the entries of Notes.Breaking, the note describing the breaking
+	// change
+	Description string
+	Body        string
+	Breaking    bool
+	// whether the type is hidden from changelogs
+	Hidden bool
+	Closes []Reference
+	Refs   []Reference
+	Author Author
+}
+
+// the changes in one section of the release notes, e.g. "Features"
+type Group struct {
+	Title string
+	// the commit types listed in the section
+	Types []string
+	// ordered by scope, unscoped changes first
+	Changes []Change
+	Scopes  []ScopeGroup
+}
+
+type ScopeGroup struct {
+	// "" for unscoped changes
+	Scope   string
+	Changes []Change
+}
+
+// everything a release-notes template can show
+type Notes struct {
+	// the release's version, e.g. "v1.3.0", or "Unreleased"
+	Version  string
+	Previous string
+	// YYYY-MM-DD
+	Date string
+	// "" if there's no link comparing the release to the previous one
+	CompareURL string
+	// one change per breaking change note, including those of hidden types
+	Breaking []Change
+	// one group per changelog section of the configured types, in the order
+	// the types are configured; hidden types are left out
+	Groups []Group
+	// every conventional commit, oldest first
+	Commits []Change
+	// the commits that aren't conventional, oldest first
+	Other []Commit
+	// every issue or ticket the commits close or refer to, in order of first
+	// mention
+	References []Reference
+	// in order of their first commit
+	Authors []Author
+}
+
+// the changes of the given types, hidden or not, ordered by scope
+func (n Notes) ChangesOf(types ...string) []Change {
+	result := []Change{}
+	for _, c := range n.Commits {
+		if slices.Contains(types, c.Type) {
+			result = append(result, c)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Scope < result[j].Scope })
+	return result
+}
+
+// the changes of any types but the given ones, ordered by scope. Changes of
+// hidden types are left out unless they're breaking.
+func (n Notes) ChangesExcept(types ...string) []Change {
+	result := []Change{}
+	for _, c := range n.Commits {
+		if !slices.Contains(types, c.Type) && (!c.Hidden || c.Breaking) {
+			result = append(result, c)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Scope < result[j].Scope })
+	return result
+}
+
+func (l Links) commitURL(hash string) string {
+	if l.Commit == "" {
+		return ""
+	}
+	return strings.ReplaceAll(l.Commit, "{{hash}}", hash)
+}
+
+func (l Links) reference(ref string) Reference {
+	id, numbered := strings.CutPrefix(ref, "#")
+	if l.Issue == "" || (l.NumberedIssuesOnly && !numbered) {
+		return Reference{ID: ref}
+	}
+	return Reference{ID: ref, URL: strings.ReplaceAll(l.Issue, "{{id}}", id)}
+}
+
+func (l Links) compareURL(release Release) string {
+	if l.Compare == "" || release.Previous == "" || release.Ref == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{{previousTag}}", release.Previous,
+		"{{currentTag}}", release.Ref,
+	).Replace(l.Compare)
+}
+
+// the title of the changelog section listing a commit type
+func sectionTitle(cfg *config.Cfg, commitType string) string {
+	if title := cfg.TypeInfoOf(commitType).Changelog; title != "" {
+		return title
+	}
+	return commitType
+}
+
+// sort a release's commits into breaking changes, sections, references, and
+// authors
+func Collect(cfg *config.Cfg, links Links, release Release) Notes {
+	notes := Notes{
+		Version:    release.Name,
+		Previous:   release.Previous,
+		Date:       release.Date,
+		CompareURL: links.compareURL(release),
+		Breaking:   []Change{},
+		Groups:     []Group{},
+		Commits:    []Change{},
+		Other:      []Commit{},
+		References: []Reference{},
+		Authors:    []Author{},
+	}
+	authors := map[string]int{} // email -> index in notes.Authors
+	referenced := map[string]bool{}
+	sections := map[string]*Group{}
+	for _, commit := range release.Commits {
+		if commit.Author != "" || commit.Email != "" {
+			key := strings.ToLower(commit.Email)
+			if _, ok := authors[key]; !ok {
+				authors[key] = len(notes.Authors)
+				notes.Authors = append(notes.Authors, Author{Name: commit.Author, Email: commit.Email})
+			}
+			notes.Authors[authors[key]].Commits++
+		}
+		cc, err := parser.ParseAsMuchOfCCAsPossible(commit.Message)
+		if err != nil || cc.Type == "" {
+			notes.Other = append(notes.Other, commit)
+			continue
+		}
+		change := Change{
+			Hash:        commit.Hash,
+			ShortHash:   commit.Hash,
+			URL:         links.commitURL(commit.Hash),
+			Type:        cfg.CanonicalType(cc.Type),
+			Scope:       cfg.CanonicalScope(cc.Scope),
+			Description: cc.Description,
+			Body:        strings.TrimSpace(cc.Body),
+			Breaking:    cc.BreakingChange,
+			Author:      Author{Name: commit.Author, Email: commit.Email},
+		}
+		if len(change.ShortHash) > 7 {
+			change.ShortHash = change.ShortHash[:7]
+		}
+		change.Hidden = cfg.TypeInfoOf(change.Type).Hidden
+		breakingNotes := []string{}
+		for _, footer := range cc.Footers {
+			token, value := parser.SplitFooter(footer)
+			var refs *[]Reference
+			switch {
+			case token == "BREAKING CHANGE" || token == "BREAKING-CHANGE":
+				breakingNotes = append(breakingNotes, value)
+				continue
+			case slices.Contains(closingTokens, strings.ToLower(token)):
+				refs = &change.Closes
+			case strings.EqualFold(token, "Refs") || (cfg.TicketFooter != "" && token == cfg.TicketFooter):
+				refs = &change.Refs
+			default:
+				continue
+			}
+			for _, id := range references(value) {
+				ref := links.reference(id)
+				*refs = append(*refs, ref)
+				if !referenced[id] {
+					referenced[id] = true
+					notes.References = append(notes.References, ref)
+				}
+			}
+		}
+		change.Breaking = change.Breaking || len(breakingNotes) > 0
+		if change.Breaking && len(breakingNotes) == 0 {
+			breakingNotes = append(breakingNotes, cc.Description)
+		}
+		for _, note := range breakingNotes {
+			entry := change
+			entry.Description, entry.Closes, entry.Refs = note, nil, nil
+			notes.Breaking = append(notes.Breaking, entry)
+		}
+		notes.Commits = append(notes.Commits, change)
+		if change.Hidden {
+			continue
+		}
+		title := sectionTitle(cfg, change.Type)
+		group, ok := sections[title]
+		if !ok {
+			group = &Group{Title: title}
+			sections[title] = group
+		}
+		if !slices.Contains(group.Types, change.Type) {
+			group.Types = append(group.Types, change.Type)
+		}
+		group.Changes = append(group.Changes, change)
+	}
+	sort.SliceStable(notes.Breaking, func(i, j int) bool { return notes.Breaking[i].Scope < notes.Breaking[j].Scope })
+
+	// order sections by the configured order of their types
+	titles := []string{}
+	keys, _ := config.ZippedOrderedKeyValuePairs(cfg.CommitTypes)
+	for _, commitType := range keys {
+		title := sectionTitle(cfg, commitType)
+		if _, ok := sections[title]; ok && !slices.Contains(titles, title) {
+			titles = append(titles, title)
+		}
+	}
+	unconfigured := []string{} // e.g. types that have since been removed
+	for title := range sections {
+		if !slices.Contains(titles, title) {
+			unconfigured = append(unconfigured, title)
+		}
+	}
+	sort.Strings(unconfigured)
+	for _, title := range append(titles, unconfigured...) {
+		group := sections[title]
+		sort.SliceStable(group.Changes, func(i, j int) bool { return group.Changes[i].Scope < group.Changes[j].Scope })
+		for _, change := range group.Changes {
+			last := len(group.Scopes) - 1
+			if last < 0 || group.Scopes[last].Scope != change.Scope {
+				group.Scopes = append(group.Scopes, ScopeGroup{Scope: change.Scope})
+				last++
+			}
+			group.Scopes[last].Changes = append(group.Scopes[last].Changes, change)
+		}
+		notes.Groups = append(notes.Groups, *group)
+	}
+	return notes
+}
+
+func markdownLink(text string, url string) string {
+	if url == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s)", text, url)
+}
+
+// functions for release-notes templates, besides text/template's built-ins
+var templateFuncs = template.FuncMap{
+	"join":         strings.Join,
+	"lower":        strings.ToLower,
+	"upper":        strings.ToUpper,
+	"trim":         strings.TrimSpace,
+	"markdownLink": markdownLink,
+	"markdownLinks": func(refs []Reference) string {
+		linked := make([]string, len(refs))
+		for i, ref := range refs {
+			linked[i] = markdownLink(ref.ID, ref.URL)
+		}
+		return strings.Join(linked, ", ")
+	},
+}
+
+// parse a release-notes template
+func ParseTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// one of the built-in templates named in Formats
+func BuiltinTemplate(format string) (*template.Template, error) {
+	if !slices.Contains(Formats[:], format) {
+		return nil, fmt.Errorf("unknown format %q; expected one of %s", format, strings.Join(Formats[:], ", "))
+	}
+	text, err := builtinTemplates.ReadFile("templates/" + format + ".tmpl")
+	if err != nil {
+		return nil, err
+	}
+	return ParseTemplate(format, string(text))
+}
@@ -0,0 +1,91 @@
+package commit_selector
+
+import (
+	"io"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/helpbar"
+	"github.com/skalt/git-cc/internal/i18n"
+	"github.com/skalt/git-cc/internal/single_select"
+	"github.com/skalt/git-cc/internal/utils"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// the length of the abbreviated hashes offered as options
+const shortHashLength = 7
+
+type Model struct {
+	input   single_select.Model
+	helpBar helpbar.Model
+}
+
+// the method for determining if the current input matches a commit: either
+// its header starts with the input, like `fix(api`, or each word of the input
+// starts the commit's type, scope, or hash, like `fix api`.
+func matcher(headers map[string]string, cfg *config.Cfg) func(*single_select.Model, string, string) bool {
+	return func(m *single_select.Model, query string, option string) bool {
+		header := headers[option]
+		if strings.HasPrefix(header, query) {
+			return true
+		}
+		cc, _ := parser.ParseAsMuchOfCCAsPossible(header)
+		fields := []string{option, cfg.CanonicalType(cc.Type), cfg.CanonicalScope(cc.Scope)}
+		for _, word := range strings.Fields(query) {
+			matched := false
+			for _, field := range fields {
+				if field != "" && strings.HasPrefix(field, word) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// offer commits, most recent first, by their hashes and headers
+func NewModel(prompt string, hashes []string, headers []string, cfg *config.Cfg) Model {
+	options := make([]string, len(hashes))
+	byOption := make(map[string]string, len(hashes))
+	for i, hash := range hashes {
+		options[i] = hash
+		if len(hash) > shortHashLength {
+			options[i] = hash[:shortHashLength]
+		}
+		byOption[options[i]] = headers[i]
+	}
+	return Model{
+		single_select.NewModel(
+			config.Faint(prompt),
+			"",
+			options, headers,
+			matcher(byOption, cfg),
+		),
+		helpbar.NewModel(
+			i18n.T("help.submit"), i18n.T("help.select"), i18n.T("help.cancel"),
+		),
+	}
+}
+
+// the abbreviated hash of the selected commit, or "" if nothing matches
+func (m Model) Value() string {
+	return m.input.Value()
+}
+
+func (m Model) Render(s io.StringWriter) {
+	m.input.Render(s)
+	_ = utils.Must(s.WriteString("\n"))
+	m.helpBar.Render(s)
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.helpBar, _ = m.helpBar.Update(msg)
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
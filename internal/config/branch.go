@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// what a branch name says about the commits made on it, e.g.
+// `feat/api/PAY-123-add-refunds` => {Type: "feat", Scope: "api", Ticket: "PAY-123"}
+type BranchMatch struct {
+	Type   string
+	Scope  string
+	Ticket string
+}
+
+// extract the named groups `type`, `scope`, and `ticket` from a branch name
+// using the configured `branch_pattern`.
+func (cfg *Cfg) MatchBranch(branch string) (match BranchMatch, ok bool) {
+	if cfg.BranchPattern == nil {
+		return match, false
+	}
+	groups := cfg.BranchPattern.FindStringSubmatch(branch)
+	if groups == nil {
+		return match, false
+	}
+	for i, name := range cfg.BranchPattern.SubexpNames() {
+		switch name {
+		case "type":
+			match.Type = groups[i]
+		case "scope":
+			match.Scope = groups[i]
+		case "ticket":
+			match.Ticket = groups[i]
+		}
+	}
+	return match, true
+}
+
+// check that a ticket_header_format has exactly one %s, where the ticket goes,
+// and no other verbs
+func toTicketHeaderFormat(raw interface{}) (string, error) {
+	format, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string, got %+v", raw)
+	}
+	tickets := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		switch {
+		case i < len(format) && format[i] == '%':
+		case i < len(format) && format[i] == 's':
+			tickets++
+		default:
+			return "", fmt.Errorf("%q has a verb other than %%s; use %%%% for a literal %%", format)
+		}
+	}
+	if tickets != 1 {
+		return "", fmt.Errorf("%q should have exactly one %%s for the ticket, but has %d", format, tickets)
+	}
+	return format, nil
+}
+
+// the prefix of a description that references a ticket, as configured
+func (cfg *Cfg) TicketHeader(ticket string) string {
+	return fmt.Sprintf(cfg.TicketHeaderFormat, ticket)
+}
+
+func toBranchPattern(raw interface{}) (*regexp.Regexp, error) {
+	pattern, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string, got %+v", raw)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range re.SubexpNames() {
+		switch name {
+		case "", "type", "scope", "ticket":
+		default:
+			return nil, fmt.Errorf("unknown group name %q; expected type, scope, or ticket", name)
+		}
+	}
+	return re, nil
+}
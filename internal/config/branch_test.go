@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestMatchBranch(t *testing.T) {
+	cfg := &Cfg{BranchPattern: regexp.MustCompile(`^(?P<type>\w+)/(?:(?P<scope>[a-z]+)/)?(?P<ticket>[A-Z]+-\d+)`)}
+	for _, c := range []struct {
+		branch   string
+		expected BranchMatch
+		ok       bool
+	}{
+		{"feat/api/PAY-123-add-refunds", BranchMatch{Type: "feat", Scope: "api", Ticket: "PAY-123"}, true},
+		{"fix/PAY-7", BranchMatch{Type: "fix", Ticket: "PAY-7"}, true},
+		{"main", BranchMatch{}, false},
+	} {
+		match, ok := cfg.MatchBranch(c.branch)
+		if match != c.expected || ok != c.ok {
+			fmt.Printf("%s: expected %+v, %v; got %+v, %v\n", c.branch, c.expected, c.ok, match, ok)
+			t.Fail()
+		}
+	}
+	if _, ok := (&Cfg{}).MatchBranch("feat/PAY-1"); ok {
+		fmt.Println("expected no match without a branch_pattern")
+		t.Fail()
+	}
+}
+
+func TestToTicketHeaderFormat(t *testing.T) {
+	for _, c := range []struct {
+		format string
+		valid  bool
+	}{
+		{"[%s] ", true},
+		{"%s: ", true},
+		{"100%% %s ", true},
+		{"[PAY] ", false},
+		{"%s %s ", false},
+		{"%d ", false},
+		{"%s %", false},
+	} {
+		if _, err := toTicketHeaderFormat(c.format); (err == nil) != c.valid {
+			fmt.Printf("%q: expected valid to be %v, got %v\n", c.format, c.valid, err)
+			t.Fail()
+		}
+	}
+}
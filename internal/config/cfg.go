@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -9,12 +10,15 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"slices"
 	"sort"
 	"strings"
 
 	toml "github.com/BurntSushi/toml"
 	"github.com/muesli/termenv"
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/internal/i18n"
 	"github.com/skalt/git-cc/internal/utils"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 	yaml "gopkg.in/yaml.v3"
@@ -129,13 +133,6 @@ func angularCommitTypes() *OrderedMap {
 	}
 }
 
-const (
-	HelpSubmit = "submit: tab/enter"
-	HelpBack   = "go back: shift+tab"
-	HelpCancel = "cancel: ctrl+c"
-	HelpSelect = "navigate: up/down"
-)
-
 func Faint(s string) string {
 	return termenv.String(s).Faint().String()
 }
@@ -148,12 +145,48 @@ type Cfg struct {
 	// a custom, ordered map type is needed since maps fail to preserve the
 	// insertion order of their keys: see https://go.dev/play/p/u0SB-LeqisU
 	CommitTypes *OrderedMap
-	Scopes      *OrderedMap
+	// optional details about each commit type; see Cfg.TypeInfoOf
+	TypeInfo map[string]CommitTypeInfo
+	Scopes   *OrderedMap
+	// optional details about each scope, e.g. aliases or deprecation notices
+	ScopeInfo map[string]ScopeInfo
 	// this caps the max len of the `type(scope): description`, not the body
 	// naming inspired by conventional-changelog/commitlint
 	HeaderMaxLength  int
 	EnforceMaxLength bool
-	DryRun           bool
+	// lint rules configured in addition to the built-in validation of types,
+	// scopes, and descriptions
+	Rules *Rules
+	// the name of the set of commit types to use if none are configured
+	Preset string
+	// named groups `type`, `scope`, and `ticket` to prefill from branch names
+	BranchPattern *regexp.Regexp
+	// the footer token to record a ticket from the branch name under, e.g. "Refs"
+	TicketFooter string
+	// if set, a format string like "[%s] " that prefixes the description with
+	// the ticket instead of adding a footer
+	TicketHeaderFormat string
+	// footers that commits of each type must have
+	RequiredFooters map[string][]RequiredFooter
+	// commit types, scopes, and rules that apply on some branches
+	Branches []BranchOverride
+	// the locale of the TUI's messages, e.g. "ja" or "pt-BR"; see i18n.Detect
+	Locale string
+	// templates of changelog links, e.g. "https://github.com/o/r/commit/{{hash}}".
+	// If unset, they're derived from the `origin` remote where possible.
+	CommitURLFormat  string
+	IssueURLFormat   string
+	CompareURLFormat string
+	// refs whose history mustn't be rewritten, e.g. "origin/main" or
+	// "release/*"
+	ProtectedRefs []string
+	// whether to open GIT_EDITOR on the commit message
+	Edit   bool
+	DryRun bool
+	// where each setting came from; see Cfg.Source
+	Sources map[string]string
+	// the repository the config was read in
+	Repo git.Repo
 }
 
 func (c *Cfg) Clone() Cfg {
@@ -165,15 +198,44 @@ func (c *Cfg) Clone() Cfg {
 	iter(c.Scopes, func(k string, v string) {
 		scopes.Set(k, v)
 	})
+	typeInfo := make(map[string]CommitTypeInfo, len(c.TypeInfo))
+	for k, v := range c.TypeInfo {
+		typeInfo[k] = v
+	}
+	scopeInfo := make(map[string]ScopeInfo, len(c.ScopeInfo))
+	for k, v := range c.ScopeInfo {
+		scopeInfo[k] = v
+	}
+	sources := make(map[string]string, len(c.Sources))
+	for k, v := range c.Sources {
+		sources[k] = v
+	}
 	return Cfg{
-		gitRepoRoot:      c.gitRepoRoot,
-		gitDir:           c.gitDir,
-		ConfigFile:       c.ConfigFile,
-		CommitTypes:      commitTypes,
-		Scopes:           scopes,
-		HeaderMaxLength:  c.HeaderMaxLength,
-		EnforceMaxLength: c.EnforceMaxLength,
-		DryRun:           c.DryRun,
+		gitRepoRoot:        c.gitRepoRoot,
+		gitDir:             c.gitDir,
+		ConfigFile:         c.ConfigFile,
+		CommitTypes:        commitTypes,
+		TypeInfo:           typeInfo,
+		Scopes:             scopes,
+		ScopeInfo:          scopeInfo,
+		HeaderMaxLength:    c.HeaderMaxLength,
+		EnforceMaxLength:   c.EnforceMaxLength,
+		Rules:              cloneRules(c.Rules),
+		BranchPattern:      c.BranchPattern,
+		TicketFooter:       c.TicketFooter,
+		TicketHeaderFormat: c.TicketHeaderFormat,
+		RequiredFooters:    c.RequiredFooters,
+		Branches:           c.Branches,
+		Preset:             c.Preset,
+		Locale:             c.Locale,
+		CommitURLFormat:    c.CommitURLFormat,
+		IssueURLFormat:     c.IssueURLFormat,
+		CompareURLFormat:   c.CompareURLFormat,
+		ProtectedRefs:      c.ProtectedRefs,
+		Edit:               c.Edit,
+		DryRun:             c.DryRun,
+		Sources:            sources,
+		Repo:               c.Repo,
 	}
 }
 
@@ -183,14 +245,54 @@ func (original *Cfg) merge(other *Cfg) {
 	}
 	if other.CommitTypes.Newest() != nil {
 		original.CommitTypes = other.CommitTypes
+		original.TypeInfo = other.TypeInfo
 	}
 	if other.Scopes.Newest() != nil {
 		original.Scopes = other.Scopes
+		original.ScopeInfo = other.ScopeInfo
+	}
+	if _, present := other.Sources["enforce_header_max_length"]; present {
+		original.EnforceMaxLength = other.EnforceMaxLength
 	}
-	original.EnforceMaxLength = other.EnforceMaxLength
 	if other.HeaderMaxLength > 0 {
 		original.HeaderMaxLength = other.HeaderMaxLength
 	}
+	if other.Rules != nil {
+		original.Rules = MergeRules(original.Rules, other.Rules)
+	}
+	if other.BranchPattern != nil {
+		original.BranchPattern = other.BranchPattern
+	}
+	if _, present := other.Sources["ticket_footer"]; present {
+		original.TicketFooter = other.TicketFooter
+	}
+	if _, present := other.Sources["ticket_header_format"]; present {
+		original.TicketHeaderFormat = other.TicketHeaderFormat
+	}
+	if other.RequiredFooters != nil {
+		original.RequiredFooters = other.RequiredFooters
+	}
+	if other.Branches != nil {
+		original.Branches = other.Branches
+	}
+	if _, present := other.Sources["locale"]; present {
+		original.Locale = other.Locale
+	}
+	if _, present := other.Sources["commit_url_format"]; present {
+		original.CommitURLFormat = other.CommitURLFormat
+	}
+	if _, present := other.Sources["issue_url_format"]; present {
+		original.IssueURLFormat = other.IssueURLFormat
+	}
+	if _, present := other.Sources["compare_url_format"]; present {
+		original.CompareURLFormat = other.CompareURLFormat
+	}
+	if other.ProtectedRefs != nil {
+		original.ProtectedRefs = other.ProtectedRefs
+	}
+	for key, source := range other.Sources {
+		original.setSource(key, source)
+	}
 }
 
 func ConstructDefaultFile(
@@ -227,24 +329,33 @@ func (cfg *Cfg) ReadCfgFile(mustExist bool) (err error) {
 	configFile := cfg.ConfigFile
 	if configFile == "" {
 		configFile, _, err = FindCCConfigFile(cfg.gitRepoRoot)
-		if err != nil {
-			if mustExist {
-				return err
-			} else {
-				return nil // fall back to defaults
-			}
+		if err != nil && mustExist {
+			return err
+		} // else fall back to defaults
+		if configFile != "" {
+			cfg.setSource("config_file", "search")
 		}
 	}
-	next, err := parseCCConfigurationFile(configFile)
-	if err != nil {
-		return err
+	if configFile != "" {
+		next, err := parseCCConfigurationFile(configFile)
+		if err != nil {
+			return err
+		}
+		cfg.merge(next)
 	}
-	cfg.merge(next)
-	return err
+	// git config and environment variables take precedence over the config file
+	return cfg.applyOverrides(readGitConfig(cfg.Repo), func(s Setting) bool {
+		return s.Key != "config_file" && s.Key != "preset"
+	})
 }
 
 // Initialize the global CentralStore of configuration.
 func Init(dryRun bool) (*Cfg, error) {
+	return InitIn(git.NewExecRepo(), dryRun)
+}
+
+// Initialize the global CentralStore of configuration for a repo.
+func InitIn(repo git.Repo, dryRun bool) (*Cfg, error) {
 	cfg := Cfg{
 		CommitTypes:     angularCommitTypes(),
 		Scopes:          orderedmap.New[string, string](),
@@ -252,9 +363,14 @@ func Init(dryRun bool) (*Cfg, error) {
 		//^ s.t. `git log --oneline` should remain within 80 columns w/ a 7-rune
 		// commit hash and one space before the commit message.
 		EnforceMaxLength: false,
+		Rules:            NewRules(),
+		TicketFooter:     "Refs",
+		Preset:           "angular",
+		Edit:             true,
 		DryRun:           dryRun,
+		Repo:             repo,
 	}
-	gitDir, err := getGitDir()
+	gitDir, err := getGitDir(repo)
 	if err != nil {
 		if dryRun {
 			// CentralStore.gitDir = "./.git"
@@ -264,25 +380,55 @@ func Init(dryRun bool) (*Cfg, error) {
 		}
 	}
 	cfg.gitDir = gitDir
-	repoRoot, err := GetGitRepoRoot()
+	repoRoot, err := getGitRepoRoot(repo)
 	if err != nil {
 		if dryRun {
-			cfg.gitRepoRoot = "."
+			repoRoot = "."
 		} else {
 			// fatal since we need to look for configuration there
 			return nil, err
 		}
 	}
 	cfg.gitRepoRoot = repoRoot
+	// the config file and preset determine what the config file overrides, so
+	// they need to be resolved first.
+	err = cfg.applyOverrides(readGitConfig(repo), func(s Setting) bool {
+		return s.Key == "config_file" || s.Key == "preset"
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.CommitTypes = presets[cfg.Preset]()
+	if source := cfg.Source("preset"); source != SourceDefault {
+		cfg.setSource("commit_types", "preset "+cfg.Preset+" from "+source)
+	}
+	if cfg.ConfigFile != "" && !filepath.IsAbs(cfg.ConfigFile) {
+		if strings.HasPrefix(cfg.Source("config_file"), "git config") {
+			// relative to the root of the repo, like core.hooksPath
+			cfg.ConfigFile = path.Join(cfg.gitRepoRoot, cfg.ConfigFile)
+		} else if cfg.ConfigFile, err = filepath.Abs(cfg.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
 	if err := cfg.ReadCfgFile(false); err != nil {
 		return nil, err
 	}
+	i18n.SetLocale(i18n.Detect(cfg.Locale))
+	if source := cfg.Source("commit_types"); source == SourceDefault || strings.HasPrefix(source, "preset ") {
+		cfg.CommitTypes = translateTypes(cfg.CommitTypes)
+	}
+	if branch, err := repo.Branch(); err == nil {
+		cfg.applyBranchOverrides(branch)
+	}
 	CentralStore = &cfg
 	return CentralStore, nil
 }
 
-// turn []string, map[string]string, or []map[string]string into an OrderedMap
-func toOrderedMap(raw interface{}) (om *OrderedMap, err error) {
+// turn []string, map[string]string, or []map[string]string into an OrderedMap.
+// Entries may also map to a table of details, e.g. `feat: {description: ..., bump: minor}`;
+// the "description" becomes the entry's value and the table is returned in `details`.
+func toOrderedMap(raw interface{}) (om *OrderedMap, details map[string]map[string]interface{}, err error) {
+	details = map[string]map[string]interface{}{}
 	insert := func(om *orderedmap.OrderedMap[string, string], key string, value string) (err error) {
 		if _, present := om.Set(key, value); present {
 			err = fmt.Errorf("duplicate key: %s", key)
@@ -297,6 +443,13 @@ func toOrderedMap(raw interface{}) (om *OrderedMap, err error) {
 			switch v2 := v.(type) {
 			case string:
 				kvp = append(kvp, [2]string{k, v2})
+			case map[string]interface{}:
+				description, ok := v2["description"].(string)
+				if _, present := v2["description"]; present && !ok {
+					return fmt.Errorf("unexpected description for %s: %+v", k, v2["description"])
+				}
+				kvp = append(kvp, [2]string{k, description})
+				details[k] = v2
 			default:
 				err = fmt.Errorf("unexpected type: %+v", v2)
 				return err
@@ -321,11 +474,11 @@ func toOrderedMap(raw interface{}) (om *OrderedMap, err error) {
 			switch intermediate3 := intermediate2.(type) {
 			case string:
 				if _, present := om.Set(intermediate3, ""); present {
-					return nil, fmt.Errorf("duplicate value: %s", intermediate3)
+					return nil, nil, fmt.Errorf("duplicate value: %s", intermediate3)
 				}
 			case map[string]interface{}:
 				if err = handleMap(om, intermediate3); err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 			default:
 				err = fmt.Errorf("unknown value `%v`", intermediate3)
@@ -351,7 +504,7 @@ func toOrderedMap(raw interface{}) (om *OrderedMap, err error) {
 		// 		panic(fmt.Errorf("unexpected type '%s' for key %s: '%+v'", reflect.TypeOf(intermediate1).Name(), k, v2))
 		// 	}
 		// }
-		return nil, fmt.Errorf("unexpected format: %+v => %+v", intermediate1, reflect.TypeOf(intermediate1).Name())
+		return nil, nil, fmt.Errorf("unexpected format: %+v => %+v", intermediate1, reflect.TypeOf(intermediate1).Name())
 	}
 }
 
@@ -432,35 +585,67 @@ func parseCCConfigurationFile(configFile string) (*Cfg, error) {
 		if err = toml.Unmarshal(data, &raw); err != nil {
 			return nil, err
 		}
-	// case ".json":
-	// 	return nil, fmt.Errorf("only package.json supported, %s found", configFile)
+	case ".json":
+		if err = json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
 	default:
 		// all file extensions should already be known when searching for config
 		// files
 		panic("Unsupported config file type: " + configFile)
 	}
+	cfg, err := parseRawCfg(raw, configFile)
+	if err != nil {
+		return nil, err
+	}
+	if rawBranches, present := raw["branches"]; present {
+		if cfg.Branches, err = toBranchOverrides(rawBranches, configFile); err != nil {
+			return nil, fmt.Errorf("invalid \"branches\" in %s: %w", configFile, err)
+		}
+	}
+	cfg.ConfigFile = configFile // always an absolute path
+	return cfg, nil
+}
 
+// read the settings in a decoded config file, or a section of one. `source`
+// is where the settings came from.
+func parseRawCfg(raw map[string]interface{}, source string) (*Cfg, error) {
+	var err error
 	var cfg Cfg
+	for _, key := range [...]string{
+		"commit_types", "scopes", "header_max_length", "enforce_header_max_length", "rules",
+		"branch_pattern", "ticket_footer", "ticket_header_format", "required_footers", "branches",
+		"locale", "commit_url_format", "issue_url_format", "compare_url_format", "protected_refs",
+	} {
+		if _, present := raw[key]; present {
+			cfg.setSource(key, source)
+		}
+	}
 	if rawScopes, ok := raw["scopes"]; ok {
-		scopes, err := toOrderedMap(rawScopes)
+		scopes, details, err := toOrderedMap(rawScopes)
 		if err != nil {
 			return nil, err
 		}
 		cfg.Scopes = scopes
+		if cfg.ScopeInfo, err = toScopeInfo(scopes, details); err != nil {
+			return nil, fmt.Errorf("invalid \"scopes\" in %s: %w", source, err)
+		}
 	}
 	if rawTypes, present := raw["commit_types"]; present {
-		types, err := toOrderedMap(rawTypes)
+		types, details, err := toOrderedMap(rawTypes)
 		if err != nil {
 			return nil, err
 		}
 		cfg.CommitTypes = types
+		if cfg.TypeInfo, err = toCommitTypeInfo(types, details); err != nil {
+			return nil, fmt.Errorf("invalid \"commit_types\" in %s: %w", source, err)
+		}
 	}
 	if maxLen, present := raw["header_max_length"]; present {
-		switch max := maxLen.(type) {
-		case int:
+		if max, ok := ToInt(maxLen); ok {
 			cfg.HeaderMaxLength = max
-		default:
-			return nil, fmt.Errorf("unexpected type of value \"header_max_length\" in %s: `%+v`", configFile, max)
+		} else {
+			return nil, fmt.Errorf("unexpected type of value \"header_max_length\" in %s: `%+v`", source, maxLen)
 		}
 	}
 	if enforcedLen, present := raw["enforce_header_max_length"]; present {
@@ -468,11 +653,52 @@ func parseCCConfigurationFile(configFile string) (*Cfg, error) {
 		case bool:
 			cfg.EnforceMaxLength = enforced
 		default:
-			return nil, fmt.Errorf("unexpected type for \"header_max_length_enforced\" in %s: `%+v`", configFile, enforcedLen)
+			return nil, fmt.Errorf("unexpected type for \"header_max_length_enforced\" in %s: `%+v`", source, enforcedLen)
+		}
+	}
+	if rawRules, present := raw["rules"]; present {
+		rules, err := toRules(rawRules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid \"rules\" in %s: %w", source, err)
+		}
+		cfg.Rules = rules
+	}
+	if rawPattern, present := raw["branch_pattern"]; present {
+		if cfg.BranchPattern, err = toBranchPattern(rawPattern); err != nil {
+			return nil, fmt.Errorf("invalid \"branch_pattern\" in %s: %w", source, err)
+		}
+	}
+	if rawFooters, present := raw["required_footers"]; present {
+		if cfg.RequiredFooters, err = toRequiredFooters(rawFooters); err != nil {
+			return nil, fmt.Errorf("invalid \"required_footers\" in %s: %w", source, err)
+		}
+	}
+	if rawFormat, present := raw["ticket_header_format"]; present {
+		if cfg.TicketHeaderFormat, err = toTicketHeaderFormat(rawFormat); err != nil {
+			return nil, fmt.Errorf("invalid \"ticket_header_format\" in %s: %w", source, err)
+		}
+	}
+	if rawRefs, present := raw["protected_refs"]; present {
+		if cfg.ProtectedRefs, err = toStringSlice(rawRefs); err != nil {
+			return nil, fmt.Errorf("invalid \"protected_refs\" in %s: %w", source, err)
+		}
+	}
+	for key, dest := range map[string]*string{
+		"ticket_footer":      &cfg.TicketFooter,
+		"locale":             &cfg.Locale,
+		"commit_url_format":  &cfg.CommitURLFormat,
+		"issue_url_format":   &cfg.IssueURLFormat,
+		"compare_url_format": &cfg.CompareURLFormat,
+	} {
+		if rawValue, present := raw[key]; present {
+			value, ok := rawValue.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected type for %q in %s: `%+v`", key, source, rawValue)
+			}
+			*dest = value
 		}
 	}
 
-	cfg.ConfigFile = configFile // always an absolute path
 	return &cfg, nil
 }
 
@@ -482,6 +708,7 @@ func FindCCConfigFile(gitRepoRoot string) (string, []string, error) {
 		"commit_convention.yaml",
 		"commit_convention.yml",
 		"commit_convention.toml",
+		"commit_convention.json",
 		// TODO: support commitlint config
 		// ".commitlintrc",
 		// ".commitlintrc.json",
@@ -554,21 +781,21 @@ func FindCCConfigFile(gitRepoRoot string) (string, []string, error) {
 }
 
 // find the root of the tree that git is working on
-func GetGitRepoRoot() (string, error) {
+func getGitRepoRoot(repo git.Repo) (string, error) {
 	if env := os.Getenv("GIT_WORK_TREE"); env != "" {
 		// there might be a `$GIT_COMMON_DIR?`
 		return env, nil
 	}
-	out, err := stdoutFrom("git", "rev-parse", "--show-toplevel")
-	if err != nil {
-		return "", err
-	} else {
-		return strings.TrimRight(out, "\r\n"), nil
-	}
+	return repo.Root()
+}
+
+// the root of the tree that git is working on, or "." outside a repo
+func (cfg *Cfg) RepoRoot() string {
+	return cfg.gitRepoRoot
 }
 
 // find the git directory (usually ./.git/)
-func getGitDir() (gitDir string, err error) {
+func getGitDir(repo git.Repo) (gitDir string, err error) {
 	var checked []string
 	// see https://git-scm.com/docs/git#Documentation/git.txt-codeGITCOMMONDIRcode
 	if gitDir = os.Getenv("GIT_COMMON_DIR"); gitDir != "" {
@@ -584,11 +811,9 @@ func getGitDir() (gitDir string, err error) {
 	if gitDir = os.Getenv("GIT_WORK_TREE"); gitDir != "" {
 		goto checkDirectory
 	}
-	gitDir, err = stdoutFrom("git", "rev-parse", "--absolute-git-dir")
-	if err != nil {
+	if gitDir, err = repo.GitDir(); err != nil {
 		return "", err
 	}
-	gitDir = strings.TrimSpace(gitDir)
 checkDirectory:
 	{ // follow .git files until we find a directory
 		checked = append(checked, gitDir)
@@ -620,30 +845,13 @@ checkDirectory:
 	}
 }
 
-func stdoutFrom(args ...string) (string, error) {
-	cmd := exec.Command(args[0], args[1:]...)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
-	return out.String(), err
-}
-
-func getGitVar(var_name string) (string, error) {
-	out, err := stdoutFrom("git", "var", var_name)
-	if err != nil {
-		return "", err
-	} else {
-		return strings.TrimRight(out, " \t\r\n"), err
-	}
-}
-
-func GetEditor() string {
+func GetEditor(repo git.Repo) string {
 	editor := os.Getenv("EDITOR")
 	if editor != "" {
 		return editor
 	}
 	var err error
-	editor, err = getGitVar("GIT_EDITOR")
+	editor, err = repo.Var("GIT_EDITOR")
 	if err != nil {
 		return editor
 	}
@@ -657,6 +865,16 @@ func GetEditor() string {
 	return editor
 }
 
+// the character that starts comment lines in commit messages; see
+// https://git-scm.com/docs/git-config#Documentation/git-config.txt-corecommentChar
+func (cfg *Cfg) CommentChar() string {
+	out, ok := cfg.Repo.ConfigGet("core.commentChar")
+	if !ok || out == "" || out == "auto" {
+		return "#"
+	}
+	return out
+}
+
 func GetCommitMessageFile() string {
 	out := CentralStore.gitDir
 	return strings.Join(
@@ -712,7 +930,7 @@ func EditCfgFileCmd(cfg *Cfg) *exec.Cmd {
 	editCmd := []string{}
 	// sometimes `$EDITOR` can be a script with spaces, like `code --wait`
 	// TODO: handle quotes in `$EDITOR`?
-	for _, part := range strings.Split(GetEditor(), " ") {
+	for _, part := range strings.Split(GetEditor(cfg.Repo), " ") {
 		if part != "" {
 			editCmd = append(editCmd, part)
 		}
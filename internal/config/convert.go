@@ -0,0 +1,590 @@
+package config
+
+// translating config files between yaml, toml, and json. Files are converted
+// as documents rather than through a Cfg so their order, comments, and any
+// details left to their defaults survive.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// the formats config files can be written in, by file extension
+func formatOf(configFile string) string {
+	switch filepath.Ext(configFile) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".json":
+		return "json"
+	default:
+		return ""
+	}
+}
+
+// split a document into its top-level mapping and any comment heading the file
+func unwrapDocument(doc *yaml.Node) (root *yaml.Node, header string, err error) {
+	root = doc
+	if doc.Kind == yaml.DocumentNode {
+		header = doc.HeadComment
+		if len(doc.Content) == 0 {
+			return &yaml.Node{Kind: yaml.MappingNode}, header, nil
+		}
+		root = doc.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("expected a mapping at the top level of the config file")
+	}
+	return root, header, nil
+}
+
+// render a document as yaml, toml, or json. Comments are dropped from json.
+func RenderDocument(doc *yaml.Node, format string) (string, error) {
+	switch format {
+	case "yaml", "yml":
+		buf := bytes.Buffer{}
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc); err != nil {
+			return "", err
+		}
+		return buf.String(), encoder.Close()
+	case "toml":
+		return renderTomlDocument(doc)
+	case "json":
+		return renderJsonDocument(doc)
+	default:
+		return "", fmt.Errorf("unsupported format %q; expected yaml, toml, or json", format)
+	}
+}
+
+// the first non-empty comment
+func firstComment(comments ...string) string {
+	for _, comment := range comments {
+		if comment != "" {
+			return comment
+		}
+	}
+	return ""
+}
+
+func writeTomlComment(buf *bytes.Buffer, comment string, indent string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			line = "# " + line
+		}
+		buf.WriteString(indent + line + "\n")
+	}
+}
+
+func tomlLineComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	if !strings.HasPrefix(comment, "#") {
+		comment = "# " + comment
+	}
+	return " " + comment
+}
+
+// render a node as an inline toml value
+func tomlNode(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return tomlNode(node.Alias)
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, child := range node.Content {
+			s, err := tomlNode(child)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case yaml.MappingNode:
+		pairs := make([]string, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, err := tomlKey(node.Content[i].Value)
+			if err != nil {
+				return "", err
+			}
+			v, err := tomlNode(node.Content[i+1])
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", node.Content[i].Value, err)
+			}
+			pairs = append(pairs, k+" = "+v)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}", nil
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return "", err
+		}
+		if value == nil {
+			return "", fmt.Errorf("toml has no null value (line %d)", node.Line)
+		}
+		return tomlScalar(value)
+	}
+}
+
+// the comments on a sequence item; line comments on `- key: value # ...`
+// belong to the value.
+func itemComments(item *yaml.Node) (head string, line string) {
+	head, line = item.HeadComment, item.LineComment
+	if item.Kind == yaml.MappingNode && len(item.Content) >= 2 {
+		head = firstComment(head, item.Content[0].HeadComment)
+		line = firstComment(line, item.Content[0].LineComment, item.Content[1].LineComment)
+	}
+	return head, line
+}
+
+func renderTomlDocument(doc *yaml.Node) (string, error) {
+	root, header, err := unwrapDocument(doc)
+	if err != nil {
+		return "", err
+	}
+	buf := bytes.Buffer{}
+	if header != "" {
+		writeTomlComment(&buf, header, "")
+		buf.WriteString("\n")
+	}
+	// top-level keys must precede any [table], or they'd belong to it
+	var tables []int
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Kind == yaml.MappingNode && value.Style != yaml.FlowStyle {
+			tables = append(tables, i)
+			continue
+		}
+		k, err := tomlKey(key.Value)
+		if err != nil {
+			return "", err
+		}
+		writeTomlComment(&buf, key.HeadComment, "")
+		line := tomlLineComment(firstComment(key.LineComment, value.LineComment))
+		if value.Kind == yaml.SequenceNode && value.Style != yaml.FlowStyle && len(value.Content) > 0 {
+			// one item per line, so each can keep its comments
+			buf.WriteString(k + " = [" + line + "\n")
+			for _, item := range value.Content {
+				s, err := tomlNode(item)
+				if err != nil {
+					return "", fmt.Errorf("%s: %w", key.Value, err)
+				}
+				head, line := itemComments(item)
+				writeTomlComment(&buf, head, "  ")
+				buf.WriteString("  " + s + "," + tomlLineComment(line) + "\n")
+			}
+			buf.WriteString("]\n")
+		} else {
+			s, err := tomlNode(value)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", key.Value, err)
+			}
+			buf.WriteString(k + " = " + s + line + "\n")
+		}
+		writeTomlComment(&buf, firstComment(value.FootComment, key.FootComment), "")
+	}
+	for _, i := range tables {
+		key, table := root.Content[i], root.Content[i+1]
+		k, err := tomlKey(key.Value)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString("\n")
+		writeTomlComment(&buf, key.HeadComment, "")
+		buf.WriteString("[" + k + "]" + tomlLineComment(firstComment(key.LineComment, table.LineComment)) + "\n")
+		for j := 0; j+1 < len(table.Content); j += 2 {
+			nestedKey, value := table.Content[j], table.Content[j+1]
+			nk, err := tomlKey(nestedKey.Value)
+			if err != nil {
+				return "", err
+			}
+			s, err := tomlNode(value)
+			if err != nil {
+				return "", fmt.Errorf("%s.%s: %w", key.Value, nestedKey.Value, err)
+			}
+			writeTomlComment(&buf, nestedKey.HeadComment, "")
+			line := tomlLineComment(firstComment(nestedKey.LineComment, value.LineComment))
+			buf.WriteString(nk + " = " + s + line + "\n")
+		}
+		writeTomlComment(&buf, firstComment(table.FootComment, key.FootComment), "")
+	}
+	return buf.String(), nil
+}
+
+// write a node as indented json in the node's order
+func writeJsonNode(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return writeJsonNode(buf, node.Alias, indent)
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		return writeJsonNode(buf, node.Content[0], indent)
+	case yaml.SequenceNode, yaml.MappingNode:
+		open, close, step := "[", "]", 1
+		if node.Kind == yaml.MappingNode {
+			open, close, step = "{", "}", 2
+		}
+		if len(node.Content) == 0 {
+			buf.WriteString(open + close)
+			return nil
+		}
+		buf.WriteString(open + "\n")
+		for i := 0; i+step-1 < len(node.Content); i += step {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(indent + "  ")
+			if node.Kind == yaml.MappingNode {
+				if err := writeJsonScalar(buf, node.Content[i].Value); err != nil {
+					return err
+				}
+				buf.WriteString(": ")
+			}
+			if err := writeJsonNode(buf, node.Content[i+step-1], indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + close)
+		return nil
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		return writeJsonScalar(buf, value)
+	}
+}
+
+func writeJsonScalar(buf *bytes.Buffer, value interface{}) error {
+	scalar := bytes.Buffer{}
+	encoder := json.NewEncoder(&scalar)
+	encoder.SetEscapeHTML(false) // keep regular expressions' `<name>` legible
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(scalar.Bytes(), []byte("\n")))
+	return nil
+}
+
+func renderJsonDocument(doc *yaml.Node) (string, error) {
+	buf := bytes.Buffer{}
+	if err := writeJsonNode(&buf, doc, ""); err != nil {
+		return "", err
+	}
+	buf.WriteString("\n")
+	return buf.String(), nil
+}
+
+// drop any styles, e.g. the flow style of everything parsed from json
+func resetStyles(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyles(child)
+	}
+}
+
+// build a node from a decoded toml value. `order` maps the paths of keys to
+// where they appear in the file; keys missing from it are sorted.
+func tomlValueNode(value interface{}, path []string, order map[string]int) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		position := func(key string) int {
+			if i, ok := order[strings.Join(append(path, key), "\x00")]; ok {
+				return i
+			}
+			return len(order)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			if pi, pj := position(keys[i]), position(keys[j]); pi != pj {
+				return pi < pj
+			}
+			return keys[i] < keys[j]
+		})
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range keys {
+			child, err := tomlValueNode(v[key], append(path, key), order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		return node, nil
+	case []map[string]interface{}: // [[arrays of tables]]
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return tomlValueNode(items, path, order)
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := tomlValueNode(item, path, order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	default:
+		node := &yaml.Node{}
+		err := node.Encode(v)
+		return node, err
+	}
+}
+
+var (
+	tomlTableLine = regexp.MustCompile(`^\s*\[\s*([A-Za-z0-9_-]+|"[^"]*")\s*\]`)
+	tomlKeyLine   = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+|"[^"]*")\s*=\s*(.*)$`)
+)
+
+// the comment at the end of a line of toml, if any, ignoring `#` in strings
+func tomlTrailingComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return strings.TrimSpace(line[i:])
+		}
+	}
+	return ""
+}
+
+func unquoteTomlKey(key string) string {
+	return strings.Trim(key, `"`)
+}
+
+// the value node of `key` in a mapping node
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// copy comments from toml source onto the equivalent nodes: comments above or
+// beside top-level keys, [tables], keys within tables, and the items of
+// multi-line arrays.
+func attachTomlComments(doc *yaml.Node, root *yaml.Node, source string) {
+	var pending []string
+	take := func() string {
+		comment := strings.Join(pending, "\n")
+		pending = nil
+		return comment
+	}
+	seenKey := false
+	var table *yaml.Node // the current [table], if any
+	var array *yaml.Node // the current multi-line array, if any
+	item := 0
+	for _, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			if !seenKey && len(pending) > 0 {
+				doc.HeadComment = take() // a comment heading the file
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			pending = append(pending, trimmed)
+		case array != nil:
+			if strings.HasPrefix(trimmed, "]") {
+				array = nil
+				pending = nil
+				continue
+			}
+			if item < len(array.Content) {
+				node := array.Content[item]
+				target := node
+				if node.Kind == yaml.MappingNode && len(node.Content) >= 2 {
+					target = node.Content[1]
+				}
+				node.HeadComment = take()
+				target.LineComment = tomlTrailingComment(line)
+			}
+			item++
+		default:
+			if match := tomlTableLine.FindStringSubmatch(line); match != nil {
+				seenKey = true
+				key, value := mappingValue(root, unquoteTomlKey(match[1]))
+				if key != nil {
+					key.HeadComment = take()
+					key.LineComment = tomlTrailingComment(line)
+				}
+				table = value
+				continue
+			}
+			match := tomlKeyLine.FindStringSubmatch(line)
+			if match == nil {
+				pending = nil
+				continue
+			}
+			seenKey = true
+			parent := root
+			if table != nil {
+				parent = table
+			}
+			key, value := mappingValue(parent, unquoteTomlKey(match[1]))
+			if key == nil {
+				pending = nil
+				continue
+			}
+			key.HeadComment = take()
+			rest := strings.TrimSpace(match[2])
+			if value.Kind == yaml.SequenceNode && strings.HasPrefix(rest, "[") && !strings.Contains(rest, "]") {
+				array, item = value, 0
+			}
+			key.LineComment = tomlTrailingComment(line)
+		}
+	}
+}
+
+// read a config file as a document that keeps its order and comments
+func readDocument(configFile string) (*yaml.Node, error) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	switch formatOf(configFile) {
+	case "yaml", "json": // json is yaml
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, err
+		}
+		if doc.Kind == 0 { // an empty file
+			doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+		}
+		if formatOf(configFile) == "json" {
+			resetStyles(doc)
+			flowShortSequences(doc)
+		}
+	case "toml":
+		var raw map[string]interface{}
+		meta, err := toml.Decode(string(data), &raw)
+		if err != nil {
+			return nil, err
+		}
+		order := map[string]int{}
+		for i, key := range meta.Keys() {
+			order[strings.Join(key, "\x00")] = i
+		}
+		root, err := tomlValueNode(raw, nil, order)
+		if err != nil {
+			return nil, err
+		}
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+		attachTomlComments(doc, root, string(data))
+		flowShortSequences(doc)
+	default:
+		return nil, fmt.Errorf("unsupported config file type: %s", configFile)
+	}
+	return doc, nil
+}
+
+// a comparable rendering of the settings a config file sets
+func (cfg *Cfg) fingerprint() (string, error) {
+	keys := make([]string, 0, len(cfg.Sources))
+	for key := range cfg.Sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := []Entry{}
+	for _, entry := range cfg.Entries() {
+		if _, set := cfg.Sources[entry.Key]; set {
+			entry.Source = ""
+			entries = append(entries, entry)
+		}
+	}
+	rendered, err := RenderEntries(entries, "json")
+	return strings.Join(keys, ",") + "\n" + rendered, err
+}
+
+// translate a config file into another format, checking that the result
+// configures exactly the same things. Returns the path the result belongs at
+// and its contents; nothing is written.
+func ConvertCfgFile(configFile string, format string) (target string, contents string, err error) {
+	if format == "yml" {
+		format = "yaml"
+	}
+	from := formatOf(configFile)
+	if from == format {
+		return "", "", fmt.Errorf("%s is already %s", configFile, format)
+	}
+	original, err := parseCCConfigurationFile(configFile)
+	if err != nil {
+		return "", "", err
+	}
+	doc, err := readDocument(configFile)
+	if err != nil {
+		return "", "", err
+	}
+	if contents, err = RenderDocument(doc, format); err != nil {
+		return "", "", err
+	}
+	target = strings.TrimSuffix(configFile, filepath.Ext(configFile)) + "." + format
+
+	// parse the result back to check nothing was lost in translation
+	dir, err := os.MkdirTemp("", "git-cc-convert")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(dir)
+	roundTrip := filepath.Join(dir, filepath.Base(target))
+	if err = os.WriteFile(roundTrip, []byte(contents), 0o644); err != nil {
+		return "", "", err
+	}
+	converted, err := parseCCConfigurationFile(roundTrip)
+	if err != nil {
+		return "", "", fmt.Errorf("the %s version of %s doesn't parse: %w", format, configFile, err)
+	}
+	expected, err := original.fingerprint()
+	if err != nil {
+		return "", "", err
+	}
+	actual, err := converted.fingerprint()
+	if err != nil {
+		return "", "", err
+	}
+	if expected != actual {
+		return "", "", fmt.Errorf(
+			"the %s version of %s would configure something different:\n%s\ninstead of\n%s",
+			format, configFile, actual, expected,
+		)
+	}
+	return target, contents, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var exampleYaml = `## commit conventions
+
+commit_types: # in order of frequency
+  # the most common
+  - fix: fixes a bug # patch
+  - feat:
+      description: adds a feature
+      aliases: [feature]
+scopes:
+  - cli: the CLI
+  - legacy:
+      description: the old CLI
+      deprecated: use cli
+header_max_length: 60 # short!
+branch_pattern: ^(?P<type>\w+)/(?P<ticket>[A-Z]+-\d+)
+# toml requires tables to follow other keys
+rules:
+  subject-full-stop: [error, never, .]
+  header-min-length: [warn, always, 10]
+required_footers:
+  fix:
+    - token: Refs
+      pattern: ^[A-Z]+-\d+$
+`
+
+func TestConvertCfgFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, contents string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(contents), 0o644); err != nil {
+			fmt.Printf("%v\n", err)
+			t.FailNow()
+		}
+		return file
+	}
+	convert := func(file string, format string) string {
+		target, contents, err := ConvertCfgFile(file, format)
+		if err != nil {
+			fmt.Printf("%s -> %s: %v\n", file, format, err)
+			t.FailNow()
+		}
+		return write(filepath.Base(target), contents)
+	}
+	original := write("commit_convention.yaml", exampleYaml)
+	t.Run("yaml -> toml -> yaml keeps order and comments", func(t *testing.T) {
+		toml := convert(original, "toml")
+		if err := os.Rename(original, original+".bak"); err != nil {
+			t.FailNow()
+		}
+		defer os.Rename(original+".bak", original)
+		roundTrip, _ := os.ReadFile(convert(toml, "yaml"))
+		if string(roundTrip) != exampleYaml {
+			fmt.Printf("expected:\n%s\ngot:\n%s\n", exampleYaml, roundTrip)
+			t.Fail()
+		}
+	})
+	t.Run("yaml -> json parses the same", func(t *testing.T) {
+		json := convert(original, "json")
+		contents, _ := os.ReadFile(json)
+		if strings.Contains(string(contents), "#") {
+			fmt.Printf("unexpected comment in\n%s\n", contents)
+			t.Fail()
+		}
+	})
+	t.Run("refuses to lose information", func(t *testing.T) {
+		file := write("lossy.yaml", "ticket_footer: Refs\nunknown: ~\n")
+		if _, _, err := ConvertCfgFile(file, "toml"); err == nil {
+			fmt.Printf("expected an error converting null to toml\n")
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+// a footer every commit of a given type must have, e.g.
+//
+//	required_footers:
+//	  fix:
+//	    - token: Refs
+//	      pattern: '^[A-Z]+-\d+$'
+//	      prompt: "Which ticket does this fix?"
+type RequiredFooter struct {
+	Token string
+	// if set, the footer's value must match this pattern
+	Pattern *regexp.Regexp
+	// what to ask for when the footer is missing
+	Prompt string
+}
+
+// whether `value` is acceptable for this footer
+func (f RequiredFooter) Accepts(value string) bool {
+	return value != "" && (f.Pattern == nil || f.Pattern.MatchString(value))
+}
+
+// the required footers of the commit type that `footers` lack or that have
+// unacceptable values.
+func (cfg *Cfg) MissingFooters(commitType string, footers []string) (missing []RequiredFooter) {
+	for _, required := range cfg.RequiredFooters[commitType] {
+		found := false
+		for _, footer := range footers {
+			if token, value := parser.SplitFooter(footer); token == required.Token && required.Accepts(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+func toRequiredFooter(raw interface{}) (footer RequiredFooter, err error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return footer, fmt.Errorf("expected {token, pattern, prompt}, got %+v", raw)
+	}
+	for key, value := range m {
+		s, ok := value.(string)
+		if !ok {
+			return footer, fmt.Errorf("unexpected value for %s: %+v", key, value)
+		}
+		switch key {
+		case "token":
+			footer.Token = s
+		case "pattern":
+			if footer.Pattern, err = regexp.Compile(s); err != nil {
+				return footer, err
+			}
+		case "prompt":
+			footer.Prompt = s
+		default:
+			return footer, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	if footer.Token == "" {
+		return footer, fmt.Errorf("missing token in %+v", raw)
+	}
+	if footer.Prompt == "" {
+		footer.Prompt = footer.Token + ":"
+	}
+	return footer, nil
+}
+
+func toRequiredFooters(raw interface{}) (map[string][]RequiredFooter, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping of commit types to footers, got %+v", raw)
+	}
+	result := make(map[string][]RequiredFooter, len(m))
+	types := make([]string, 0, len(m))
+	for commitType := range m {
+		types = append(types, commitType)
+	}
+	sort.Strings(types) // report errors deterministically
+	for _, commitType := range types {
+		var items []interface{}
+		switch v := m[commitType].(type) {
+		case []interface{}:
+			items = v
+		case []map[string]interface{}: // toml arrays of tables
+			for _, item := range v {
+				items = append(items, item)
+			}
+		default:
+			items = []interface{}{v}
+		}
+		for _, item := range items {
+			footer, err := toRequiredFooter(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", commitType, err)
+			}
+			result[commitType] = append(result[commitType], footer)
+		}
+	}
+	return result, nil
+}
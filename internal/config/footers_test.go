@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMissingFooters(t *testing.T) {
+	cfg := &Cfg{RequiredFooters: map[string][]RequiredFooter{
+		"fix": {
+			{Token: "Refs", Pattern: regexp.MustCompile(`^[A-Z]+-\d+$`)},
+			{Token: "Reviewed-by"},
+		},
+	}}
+	for _, c := range []struct {
+		commitType string
+		footers    []string
+		expected   []string
+	}{
+		{"fix", []string{"Refs: PAY-1", "Reviewed-by: Ada"}, nil},
+		{"fix", []string{"Reviewed-by: Ada", "Refs: PAY-1"}, nil},
+		{"fix", []string{"Refs: PAY-1"}, []string{"Reviewed-by"}},
+		{"fix", []string{"Refs: #1", "Reviewed-by: Ada"}, []string{"Refs"}},
+		{"fix", []string{"Refs #PAY-1", "Reviewed-by: Ada"}, []string{"Refs"}},
+		{"fix", []string{"Refs:", "Reviewed-by: Ada"}, []string{"Refs"}},
+		{"fix", nil, []string{"Refs", "Reviewed-by"}},
+		{"feat", nil, nil},
+	} {
+		tokens := []string{}
+		for _, footer := range cfg.MissingFooters(c.commitType, c.footers) {
+			tokens = append(tokens, footer.Token)
+		}
+		if strings.Join(tokens, ",") != strings.Join(c.expected, ",") {
+			fmt.Printf("%s %q: expected %q missing, got %q\n", c.commitType, c.footers, c.expected, tokens)
+			t.Fail()
+		}
+	}
+}
+
+func TestToRequiredFooters(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg, err := parseYamlCfg(t, `
+required_footers:
+  fix:
+    - token: Refs
+      pattern: ^[A-Z]+-\d+$
+      prompt: Which ticket does this fix?
+    - token: Reviewed-by
+  perf:
+    token: Benchmark
+`)
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		fix, perf := cfg.RequiredFooters["fix"], cfg.RequiredFooters["perf"]
+		if len(fix) != 2 || len(perf) != 1 {
+			fmt.Printf("unexpected footers %+v\n", cfg.RequiredFooters)
+			t.FailNow()
+		}
+		if fix[0].Token != "Refs" || fix[0].Pattern.String() != `^[A-Z]+-\d+$` || fix[0].Prompt != "Which ticket does this fix?" {
+			fmt.Printf("unexpected first fix footer %+v\n", fix[0])
+			t.Fail()
+		}
+		if fix[1].Pattern != nil || fix[1].Prompt != "Reviewed-by:" {
+			fmt.Printf("expected the prompt to default to the token, got %+v\n", fix[1])
+			t.Fail()
+		}
+		if perf[0].Token != "Benchmark" {
+			fmt.Printf("expected a single footer to be accepted, got %+v\n", perf)
+			t.Fail()
+		}
+	})
+	for _, c := range []struct {
+		name, footers, problem string
+	}{
+		{"not a mapping", "[fix]", "expected a mapping of commit types to footers"},
+		{"missing token", "{fix: [{pattern: x}]}", "fix: missing token"},
+		{"invalid pattern", "{fix: [{token: Refs, pattern: '('}]}", "fix: error parsing regexp"},
+		{"unknown key", "{fix: [{token: Refs, value: x}]}", `fix: unknown key "value"`},
+		{"non-string value", "{fix: [{token: 1}]}", "fix: unexpected value for token"},
+		{"not a footer", "{fix: [Refs]}", "fix: expected {token, pattern, prompt}"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseYamlCfg(t, "required_footers: "+c.footers)
+			if err == nil || !strings.Contains(err.Error(), c.problem) {
+				fmt.Printf("expected an error like %q, got %v\n", c.problem, err)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+// the directory git runs hooks from. `git rev-parse --git-path hooks` accounts
+// for `core.hooksPath` and for worktrees, which share their main worktree's
+// hooks; older gits fall back to `$GIT_DIR/hooks`.
+func HooksDir(repo git.Repo) (string, error) {
+	dir, err := repo.GitPath("hooks")
+	if err != nil || dir == "" {
+		gitDir, err := getGitDir(repo)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(gitDir, "hooks"), nil
+	}
+	return dir, nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// settings that apply only on branches whose names match a glob, e.g.
+//
+//	branches:
+//	  release/*:
+//	    commit_types: [fix, perf, revert, docs]
+//	    rules:
+//	      body-leading-blank: [error, always]
+type BranchOverride struct {
+	// a pattern like `release/*`; see path.Match
+	Glob string
+	// the commit types, scopes, and rules to use on matching branches
+	Cfg *Cfg
+	// types and scopes listed by name alone, which keep their descriptions
+	// and details from the rest of the config
+	inheritTypes  map[string]bool
+	inheritScopes map[string]bool
+}
+
+// the settings a `branches:` section may override
+var branchOverridable = map[string]bool{"commit_types": true, "scopes": true, "rules": true}
+
+// the names in a list that are given without a description
+func bareNames(raw interface{}) map[string]bool {
+	names := map[string]bool{}
+	if items, ok := raw.([]interface{}); ok {
+		for _, item := range items {
+			if name, ok := item.(string); ok {
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// parse a `branches:` section. Overrides are ordered from the least to the
+// most specific glob, so that more specific globs win.
+func toBranchOverrides(raw interface{}, source string) ([]BranchOverride, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping of branch globs to settings, got %+v", raw)
+	}
+	globs := make([]string, 0, len(m))
+	for glob := range m {
+		globs = append(globs, glob)
+	}
+	sort.Slice(globs, func(i, j int) bool {
+		if len(globs[i]) != len(globs[j]) {
+			return len(globs[i]) < len(globs[j])
+		}
+		return globs[i] < globs[j]
+	})
+	overrides := make([]BranchOverride, 0, len(globs))
+	for _, glob := range globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", glob, err)
+		}
+		section, ok := m[glob].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a mapping of settings, got %+v", glob, m[glob])
+		}
+		for key := range section {
+			if !branchOverridable[key] {
+				return nil, fmt.Errorf("%s: %q can't be overridden per branch; expected commit_types, scopes, or rules", glob, key)
+			}
+		}
+		cfg, err := parseRawCfg(section, fmt.Sprintf("branches.%s in %s", glob, source))
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, BranchOverride{
+			Glob:          glob,
+			Cfg:           cfg,
+			inheritTypes:  bareNames(section["commit_types"]),
+			inheritScopes: bareNames(section["scopes"]),
+		})
+	}
+	return overrides, nil
+}
+
+// copy the descriptions of names listed alone from `base`
+func inherit(om *OrderedMap, names map[string]bool, base *OrderedMap) *OrderedMap {
+	if om == nil {
+		return nil
+	}
+	result := orderedmap.New[string, string]()
+	iter(om, func(name string, description string) {
+		if baseDescription, ok := base.Get(name); ok && names[name] && description == "" {
+			description = baseDescription
+		}
+		result.Set(name, description)
+	})
+	return result
+}
+
+// apply the overrides of every glob that matches the branch
+func (cfg *Cfg) applyBranchOverrides(branch string) {
+	for _, override := range cfg.Branches {
+		if matched, _ := path.Match(override.Glob, branch); !matched {
+			continue
+		}
+		next := *override.Cfg
+		next.CommitTypes = inherit(next.CommitTypes, override.inheritTypes, cfg.CommitTypes)
+		next.TypeInfo = make(map[string]CommitTypeInfo, len(override.Cfg.TypeInfo))
+		for name, info := range override.Cfg.TypeInfo {
+			if _, ok := cfg.TypeInfo[name]; ok && override.inheritTypes[name] {
+				info = cfg.TypeInfo[name]
+			}
+			next.TypeInfo[name] = info
+		}
+		next.Scopes = inherit(next.Scopes, override.inheritScopes, cfg.Scopes)
+		next.ScopeInfo = make(map[string]ScopeInfo, len(override.Cfg.ScopeInfo))
+		for name, info := range override.Cfg.ScopeInfo {
+			if _, ok := cfg.ScopeInfo[name]; ok && override.inheritScopes[name] {
+				info = cfg.ScopeInfo[name]
+			}
+			next.ScopeInfo[name] = info
+		}
+		cfg.merge(&next)
+	}
+}
+
+// describe the overrides as they'd be written in a config file
+func (cfg *Cfg) branchesValue() map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, override := range cfg.Branches {
+		section := map[string]interface{}{}
+		o := override.Cfg
+		if _, set := o.Sources["commit_types"]; set {
+			types := o.commitTypesValue()
+			for i, name := range keysOf(o.CommitTypes) {
+				if override.inheritTypes[name] {
+					types[i] = name
+				}
+			}
+			section["commit_types"] = types
+		}
+		if _, set := o.Sources["scopes"]; set {
+			scopes := o.scopesValue()
+			for i, name := range keysOf(o.Scopes) {
+				if override.inheritScopes[name] {
+					scopes[i] = name
+				}
+			}
+			section["scopes"] = scopes
+		}
+		if _, set := o.Sources["rules"]; set {
+			section["rules"] = rulesValue(o.Rules)
+		}
+		result[override.Glob] = section
+	}
+	return result
+}
+
+func keysOf(om *OrderedMap) []string {
+	keys, _ := ZippedOrderedKeyValuePairs(om)
+	return keys
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var branchesYaml = `
+commit_types:
+  - fix: fixes a bug
+  - feat: adds a feature
+  - docs: documents
+scopes:
+  - cli: the CLI
+  - api: the API
+rules:
+  body-leading-blank: [warn, always]
+  header-max-length: [error, always, 72]
+branches:
+  "*":
+    scopes: [cli]
+  release/*:
+    commit_types: [fix, {perf: speeds things up}]
+    rules:
+      body-leading-blank: [error, always]
+  release/v1.*:
+    commit_types: [fix]
+    rules:
+      header-max-length: [error, always, 50]
+`
+
+func TestApplyBranchOverrides(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "commit_convention.yaml")
+	if err := os.WriteFile(file, []byte(branchesYaml), 0o644); err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	parse := func() *Cfg {
+		cfg, err := parseCCConfigurationFile(file)
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		return cfg
+	}
+	globs := []string{}
+	for _, override := range parse().Branches {
+		globs = append(globs, override.Glob)
+	}
+	if !slices.Equal(globs, []string{"*", "release/*", "release/v1.*"}) {
+		fmt.Printf("expected the least specific glob first, got %q\n", globs)
+		t.Fail()
+	}
+	for _, c := range []struct {
+		branch string
+		types  []string
+		scopes []string
+		// the level of body-leading-blank and the limit of header-max-length
+		bodyLeadingBlank RuleLevel
+		headerMaxLength  string
+	}{
+		{"feat/x", []string{"fix", "feat", "docs"}, []string{"cli", "api"}, RuleWarn, "72"},
+		{"main", []string{"fix", "feat", "docs"}, []string{"cli"}, RuleWarn, "72"},
+		// like path.Match, `*` doesn't match across a `/`
+		{"release/2", []string{"fix", "perf"}, []string{"cli", "api"}, RuleError, "72"},
+		{"release/v1.2", []string{"fix"}, []string{"cli", "api"}, RuleError, "50"},
+	} {
+		t.Run(c.branch, func(t *testing.T) {
+			cfg := parse()
+			cfg.applyBranchOverrides(c.branch)
+			if actual := keysOf(cfg.CommitTypes); !slices.Equal(actual, c.types) {
+				fmt.Printf("expected types %q, got %q\n", c.types, actual)
+				t.Fail()
+			}
+			if actual := keysOf(cfg.Scopes); !slices.Equal(actual, c.scopes) {
+				fmt.Printf("expected scopes %q, got %q\n", c.scopes, actual)
+				t.Fail()
+			}
+			if rule, _ := cfg.Rules.Get("body-leading-blank"); rule.Level != c.bodyLeadingBlank {
+				fmt.Printf("expected body-leading-blank to be %s, got %s\n", c.bodyLeadingBlank, rule.Level)
+				t.Fail()
+			}
+			if rule, _ := cfg.Rules.Get("header-max-length"); fmt.Sprint(rule.Value) != c.headerMaxLength {
+				fmt.Printf("expected header-max-length %s, got %v\n", c.headerMaxLength, rule.Value)
+				t.Fail()
+			}
+		})
+	}
+	t.Run("names listed alone keep their descriptions", func(t *testing.T) {
+		cfg := parse()
+		cfg.applyBranchOverrides("release/v1.2")
+		if description, _ := cfg.CommitTypes.Get("fix"); description != "fixes a bug" {
+			fmt.Printf("expected fix to keep its description, got %q\n", description)
+			t.Fail()
+		}
+		cfg = parse()
+		cfg.applyBranchOverrides("main")
+		if description, _ := cfg.Scopes.Get("cli"); description != "the CLI" {
+			fmt.Printf("expected cli to keep its description, got %q\n", description)
+			t.Fail()
+		}
+		cfg = parse()
+		cfg.applyBranchOverrides("release/2")
+		if description, _ := cfg.CommitTypes.Get("perf"); description != "speeds things up" {
+			fmt.Printf("expected perf's own description, got %q\n", description)
+			t.Fail()
+		}
+	})
+}
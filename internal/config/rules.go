@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// how seriously to take a rule violation; see
+// https://commitlint.js.org/reference/rules-configuration.html
+type RuleLevel int
+
+const (
+	RuleOff RuleLevel = iota
+	RuleWarn
+	RuleError
+)
+
+func (l RuleLevel) String() string {
+	switch l {
+	case RuleWarn:
+		return "warn"
+	case RuleError:
+		return "error"
+	default:
+		return "off"
+	}
+}
+
+// the configuration of a single lint rule, e.g. `subject-case: [error, never, upper-case]`
+type RuleConfig struct {
+	Level RuleLevel
+	// either "always" or "never"; "never" inverts the rule's condition. Empty
+	// when the rule was given as just a level, e.g. `subject-empty: error`, so
+	// that it keeps the applicability and value of the rule it overrides.
+	Applicable string
+	// rule-specific options, e.g. a maximum length or a list of allowed cases
+	Value interface{}
+}
+
+type Rules = orderedmap.OrderedMap[string, RuleConfig]
+
+func NewRules() *Rules {
+	return orderedmap.New[string, RuleConfig]()
+}
+
+func cloneRules(rules *Rules) *Rules {
+	result := NewRules()
+	if rules == nil {
+		return result
+	}
+	for pair := rules.Oldest(); pair != nil; pair = pair.Next() {
+		result.Set(pair.Key, pair.Value)
+	}
+	return result
+}
+
+// overwrite the rules in `original` with any rules of the same name in
+// `other`. Rules in `other` given as just a level only change the level.
+func MergeRules(original *Rules, other *Rules) *Rules {
+	result := cloneRules(original)
+	if other == nil {
+		return result
+	}
+	for pair := other.Oldest(); pair != nil; pair = pair.Next() {
+		rule := pair.Value
+		if base, ok := result.Get(pair.Key); ok && rule.Applicable == "" {
+			rule.Applicable, rule.Value = base.Applicable, base.Value
+		}
+		result.Set(pair.Key, rule)
+	}
+	return result
+}
+
+// accept either commitlint's numeric levels or their names
+func toRuleLevel(raw interface{}) (RuleLevel, error) {
+	if n, ok := ToInt(raw); ok {
+		if n < int(RuleOff) || n > int(RuleError) {
+			return RuleOff, fmt.Errorf("rule level out of range [0, 2]: %d", n)
+		}
+		return RuleLevel(n), nil
+	}
+	switch raw {
+	case "off", "disabled":
+		return RuleOff, nil
+	case "warn", "warning":
+		return RuleWarn, nil
+	case "error":
+		return RuleError, nil
+	}
+	return RuleOff, fmt.Errorf("unknown rule level: %+v", raw)
+}
+
+// read a whole number from a decoded config value: yaml decodes integers as
+// int, toml as int64, and json as float64
+func ToInt(raw interface{}) (int, bool) {
+	switch n := raw.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// parse a single rule: either a bare level or a [level, applicable, value] list.
+func toRuleConfig(raw interface{}) (rule RuleConfig, err error) {
+	switch r := raw.(type) {
+	case []interface{}:
+		if len(r) == 0 || len(r) > 3 {
+			return rule, fmt.Errorf("expected [level, applicable, value], got %+v", r)
+		}
+		if rule.Level, err = toRuleLevel(r[0]); err != nil {
+			return rule, err
+		}
+		rule.Applicable = "always"
+		if len(r) > 1 {
+			switch r[1] {
+			case "always", "never":
+				rule.Applicable = r[1].(string)
+			default:
+				return rule, fmt.Errorf("expected \"always\" or \"never\", got %+v", r[1])
+			}
+		}
+		if len(r) > 2 {
+			rule.Value = r[2]
+		}
+	default:
+		rule.Level, err = toRuleLevel(r)
+	}
+	return rule, err
+}
+
+func toRules(raw interface{}) (*Rules, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping of rule names to rules, got %+v", raw)
+	}
+	// alphabetize the rule names to keep output deterministic
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rules := orderedmap.New[string, RuleConfig](orderedmap.WithCapacity[string, RuleConfig](len(m)))
+	for _, name := range names {
+		rule, err := toRuleConfig(m[name])
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		rules.Set(name, rule)
+	}
+	return rules, nil
+}
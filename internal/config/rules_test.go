@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestToRuleConfig(t *testing.T) {
+	for _, c := range []struct {
+		raw      interface{}
+		expected RuleConfig
+		valid    bool
+	}{
+		{"error", RuleConfig{Level: RuleError}, true},
+		{2, RuleConfig{Level: RuleError}, true},
+		{int64(1), RuleConfig{Level: RuleWarn}, true},
+		{float64(0), RuleConfig{Level: RuleOff}, true},
+		{[]interface{}{"warn"}, RuleConfig{Level: RuleWarn, Applicable: "always"}, true},
+		{[]interface{}{2, "never"}, RuleConfig{Level: RuleError, Applicable: "never"}, true},
+		{[]interface{}{2, "always", 10}, RuleConfig{Level: RuleError, Applicable: "always", Value: 10}, true},
+		{float64(1.5), RuleConfig{}, false},
+		{3, RuleConfig{}, false},
+		{"fatal", RuleConfig{}, false},
+		{[]interface{}{2, "sometimes"}, RuleConfig{}, false},
+		{[]interface{}{}, RuleConfig{}, false},
+	} {
+		rule, err := toRuleConfig(c.raw)
+		if (err == nil) != c.valid || (c.valid && rule != c.expected) {
+			fmt.Printf("%#v: expected %+v (valid: %v), got %+v, %v\n", c.raw, c.expected, c.valid, rule, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestToInt(t *testing.T) {
+	for _, c := range []struct {
+		raw      interface{}
+		expected int
+		ok       bool
+	}{
+		{72, 72, true},
+		{int64(72), 72, true},
+		{uint64(72), 72, true},
+		{float64(72), 72, true},
+		{72.5, 0, false},
+		{"72", 0, false},
+	} {
+		if actual, ok := ToInt(c.raw); actual != c.expected || ok != c.ok {
+			fmt.Printf("%#v: expected %d, %v; got %d, %v\n", c.raw, c.expected, c.ok, actual, ok)
+			t.Fail()
+		}
+	}
+}
+
+func TestMergeRules(t *testing.T) {
+	original := NewRules()
+	original.Set("subject-empty", RuleConfig{Level: RuleError, Applicable: "never"})
+	original.Set("header-max-length", RuleConfig{Level: RuleWarn, Applicable: "always", Value: 72})
+	other := NewRules()
+	other.Set("subject-empty", RuleConfig{Level: RuleWarn})
+	other.Set("header-max-length", RuleConfig{Level: RuleError, Applicable: "always", Value: 50})
+	other.Set("body-leading-blank", RuleConfig{Level: RuleWarn})
+	merged := MergeRules(original, other)
+	for name, expected := range map[string]RuleConfig{
+		"subject-empty":      {Level: RuleWarn, Applicable: "never"},
+		"header-max-length":  {Level: RuleError, Applicable: "always", Value: 50},
+		"body-leading-blank": {Level: RuleWarn},
+	} {
+		if actual, _ := merged.Get(name); actual != expected {
+			fmt.Printf("%s: expected %+v, got %+v\n", name, expected, actual)
+			t.Fail()
+		}
+	}
+	if rule, _ := original.Get("subject-empty"); rule.Level != RuleError {
+		fmt.Println("expected the original rules to be left alone")
+		t.Fail()
+	}
+}
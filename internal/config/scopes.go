@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// optional details about a scope beyond its short description, e.g.
+//
+//	scopes:
+//	  - cmd:
+//	      description: UI for command-line invocation
+//	      aliases: [cli]
+//	  - legacy:
+//	      description: the old UI
+//	      deprecated: use cmd instead
+type ScopeInfo struct {
+	// former or alternate names of the scope, e.g. "cli" for "cmd"
+	Aliases []string
+	// if non-empty, why the scope shouldn't be used anymore
+	Deprecated string
+}
+
+// resolve an alias to the name of the scope it stands for. Unknown scopes are
+// returned unchanged.
+func (cfg *Cfg) CanonicalScope(scope string) string {
+	if _, known := cfg.Scopes.Get(scope); known {
+		return scope
+	}
+	for name, info := range cfg.ScopeInfo {
+		if slices.Contains(info.Aliases, scope) {
+			return name
+		}
+	}
+	return scope
+}
+
+// the scopes to offer in the scope selector, i.e. every non-deprecated scope
+func (cfg *Cfg) ActiveScopes() *OrderedMap {
+	result := orderedmap.New[string, string]()
+	iter(cfg.Scopes, func(name string, description string) {
+		if cfg.ScopeInfo[name].Deprecated == "" {
+			result.Set(name, description)
+		}
+	})
+	return result
+}
+
+// deprecated scopes and their deprecation messages
+func (cfg *Cfg) DeprecatedScopes() map[string]string {
+	result := map[string]string{}
+	for name, info := range cfg.ScopeInfo {
+		if info.Deprecated != "" {
+			result[name] = info.Deprecated
+		}
+	}
+	return result
+}
+
+func toScopeInfo(scopes *OrderedMap, details map[string]map[string]interface{}) (map[string]ScopeInfo, error) {
+	result := make(map[string]ScopeInfo, len(details))
+	aliased := map[string]string{}
+	for pair := scopes.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+		var info ScopeInfo
+		for key, value := range details[name] {
+			var ok bool
+			switch key {
+			case "description":
+				ok = true // already the value in `scopes`
+			case "deprecated":
+				switch v := value.(type) {
+				case string:
+					info.Deprecated, ok = v, true
+				case bool:
+					if v {
+						info.Deprecated = "deprecated"
+					}
+					ok = true
+				}
+			case "aliases":
+				var err error
+				if info.Aliases, err = toStringSlice(value); err != nil {
+					return nil, fmt.Errorf("%s.aliases: %w", name, err)
+				}
+				ok = true
+			default:
+				return nil, fmt.Errorf("%s: unknown key %q", name, key)
+			}
+			if !ok {
+				return nil, fmt.Errorf("%s: unexpected value for %s: %+v", name, key, value)
+			}
+		}
+		for _, alias := range info.Aliases {
+			if _, present := scopes.Get(alias); present {
+				return nil, fmt.Errorf("%s: alias %q is also a scope", name, alias)
+			}
+			if other, present := aliased[alias]; present {
+				return nil, fmt.Errorf("%s: alias %q is also an alias of %s", name, alias, other)
+			}
+			aliased[alias] = name
+		}
+		result[name] = info
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func parseYamlCfg(t *testing.T, contents string) (*Cfg, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal([]byte(contents), &raw); err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	return parseRawCfg(raw, "test")
+}
+
+func TestScopes(t *testing.T) {
+	cfg, err := parseYamlCfg(t, `
+scopes:
+  - cmd:
+      description: the CLI
+      aliases: [cli, command]
+  - legacy:
+      description: the old CLI
+      deprecated: use cmd instead
+  - parser: parses commits
+  - gone:
+      deprecated: true
+`)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	t.Run("aliases", func(t *testing.T) {
+		for scope, expected := range map[string]string{
+			"cmd": "cmd", "cli": "cmd", "command": "cmd", "parser": "parser", "unknown": "unknown", "": "",
+		} {
+			if actual := cfg.CanonicalScope(scope); actual != expected {
+				fmt.Printf("%q: expected %q, got %q\n", scope, expected, actual)
+				t.Fail()
+			}
+		}
+	})
+	t.Run("deprecation notices", func(t *testing.T) {
+		deprecated := cfg.DeprecatedScopes()
+		if len(deprecated) != 2 || deprecated["legacy"] != "use cmd instead" || deprecated["gone"] != "deprecated" {
+			fmt.Printf("unexpected deprecated scopes %+v\n", deprecated)
+			t.Fail()
+		}
+	})
+	t.Run("deprecated scopes aren't offered", func(t *testing.T) {
+		if actual := keysOf(cfg.ActiveScopes()); !slices.Equal(actual, []string{"cmd", "parser"}) {
+			fmt.Printf("unexpected active scopes %q\n", actual)
+			t.Fail()
+		}
+	})
+	for _, c := range []struct {
+		name, scopes, problem string
+	}{
+		{"unknown keys", "[{cmd: {desc: the CLI}}]", `unknown key "desc"`},
+		{"invalid deprecation", "[{cmd: {deprecated: 1}}]", "unexpected value for deprecated"},
+		{"invalid aliases", "[{cmd: {aliases: {cli: 1}}}]", "cmd.aliases"},
+		{"aliases that are scopes", "[{cmd: {aliases: [cli]}}, cli]", `alias "cli" is also a scope`},
+		{"shared aliases", "[{cmd: {aliases: [c]}}, {cli: {aliases: [c]}}]", `alias "c" is also an alias of cmd`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseYamlCfg(t, "scopes: "+c.scopes)
+			if err == nil || !strings.Contains(err.Error(), c.problem) {
+				fmt.Printf("expected an error like %q, got %v\n", c.problem, err)
+				t.Fail()
+			}
+		})
+	}
+}
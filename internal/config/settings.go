@@ -0,0 +1,158 @@
+package config
+
+// Scalar settings can come from (in increasing order of precedence):
+//  1. built-in defaults
+//  2. the config file (see Cfg.merge)
+//  3. `git config` keys under `cc.*`, e.g. `git config cc.headerMaxLength 50`.
+//     Git itself resolves system, global, local, and worktree config.
+//  4. `GIT_CC_*` environment variables, e.g. `GIT_CC_HEADER_MAX_LENGTH=50`
+// `cc.configFile`/`GIT_CC_CONFIG_FILE` select which config file to read, so
+// they're resolved before the config file is read.
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/skalt/git-cc/internal/git"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+const SourceDefault = "default"
+
+type Setting struct {
+	// the key used in config files, e.g. `header_max_length`
+	Key string
+	// the `git config` key, e.g. `cc.headerMaxLength`
+	GitKey string
+	// the environment variable, e.g. `GIT_CC_HEADER_MAX_LENGTH`
+	Env   string
+	set   func(cfg *Cfg, value string) error
+	Value func(cfg *Cfg) string
+}
+
+var Settings = [...]Setting{
+	{
+		Key: "config_file", GitKey: "cc.configFile", Env: "GIT_CC_CONFIG_FILE",
+		set: func(cfg *Cfg, value string) error {
+			cfg.ConfigFile = value
+			return nil
+		},
+		Value: func(cfg *Cfg) string { return cfg.ConfigFile },
+	},
+	{
+		Key: "preset", GitKey: "cc.preset", Env: "GIT_CC_PRESET",
+		set: func(cfg *Cfg, value string) error {
+			if _, ok := presets[value]; !ok {
+				return fmt.Errorf("unknown preset %q", value)
+			}
+			cfg.Preset = value
+			return nil
+		},
+		Value: func(cfg *Cfg) string { return cfg.Preset },
+	},
+	{
+		Key: "header_max_length", GitKey: "cc.headerMaxLength", Env: "GIT_CC_HEADER_MAX_LENGTH",
+		set: func(cfg *Cfg, value string) (err error) {
+			cfg.HeaderMaxLength, err = strconv.Atoi(value)
+			return err
+		},
+		Value: func(cfg *Cfg) string { return strconv.Itoa(cfg.HeaderMaxLength) },
+	},
+	{
+		Key: "enforce_header_max_length", GitKey: "cc.enforceHeaderMaxLength", Env: "GIT_CC_ENFORCE_HEADER_MAX_LENGTH",
+		set: func(cfg *Cfg, value string) (err error) {
+			cfg.EnforceMaxLength, err = parseGitBool(value)
+			return err
+		},
+		Value: func(cfg *Cfg) string { return strconv.FormatBool(cfg.EnforceMaxLength) },
+	},
+	{
+		Key: "locale", GitKey: "cc.locale", Env: "GIT_CC_LOCALE",
+		set: func(cfg *Cfg, value string) error {
+			cfg.Locale = value
+			return nil
+		},
+		Value: func(cfg *Cfg) string { return cfg.Locale },
+	},
+	{
+		Key: "edit", GitKey: "cc.edit", Env: "GIT_CC_EDIT",
+		set: func(cfg *Cfg, value string) (err error) {
+			cfg.Edit, err = parseGitBool(value)
+			return err
+		},
+		Value: func(cfg *Cfg) string { return strconv.FormatBool(cfg.Edit) },
+	},
+}
+
+// the commit types to use if no config file lists any
+var presets = map[string]func() *OrderedMap{
+	"angular": angularCommitTypes,
+	// the only types the conventional commits spec names
+	"conventional": func() *OrderedMap {
+		om := orderedmap.New[string, string]()
+		om.Set("feat", "adds a new feature")
+		om.Set("fix", "fixes a bug")
+		return om
+	},
+}
+
+// see https://git-scm.com/docs/git-config#Documentation/git-config.txt-boolean
+func parseGitBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1", "":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("not a boolean: %q", value)
+	}
+}
+
+// where the setting's current value came from
+func (cfg *Cfg) Source(key string) string {
+	if source, ok := cfg.Sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+func (cfg *Cfg) setSource(key string, source string) {
+	if cfg.Sources == nil {
+		cfg.Sources = map[string]string{}
+	}
+	cfg.Sources[key] = source
+}
+
+// read all `cc.*` keys from `git config`. The keys are lower-cased since git
+// config keys are case-insensitive.
+func readGitConfig(repo git.Repo) map[string]string {
+	if repo == nil {
+		return map[string]string{}
+	}
+	return repo.ConfigPrefixed("cc.")
+}
+
+// apply a setting from git config or the environment, if either is set.
+// `only` limits which settings to apply.
+func (cfg *Cfg) applyOverrides(gitConfig map[string]string, only func(Setting) bool) error {
+	for _, setting := range Settings {
+		if !only(setting) {
+			continue
+		}
+		if value, ok := gitConfig[strings.ToLower(setting.GitKey)]; ok {
+			if err := setting.set(cfg, value); err != nil {
+				return fmt.Errorf("git config %s: %w", setting.GitKey, err)
+			}
+			cfg.setSource(setting.Key, "git config "+setting.GitKey)
+		}
+		if value, ok := os.LookupEnv(setting.Env); ok {
+			if err := setting.set(cfg, value); err != nil {
+				return fmt.Errorf("$%s: %w", setting.Env, err)
+			}
+			cfg.setSource(setting.Key, "$"+setting.Env)
+		}
+	}
+	return nil
+}
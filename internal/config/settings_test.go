@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/git"
+)
+
+func TestSettingPrecedence(t *testing.T) {
+	// read the config of a fake repo whose config file, if any, has `contents`
+	initIn := func(t *testing.T, contents string, gitConfig map[string]string, env map[string]string) (*Cfg, string, error) {
+		for _, setting := range Settings {
+			t.Setenv(setting.Env, "")
+			os.Unsetenv(setting.Env)
+		}
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		for name, value := range env {
+			t.Setenv(name, value)
+		}
+		root := t.TempDir()
+		file := filepath.Join(root, "commit_convention.yaml")
+		if contents != "" {
+			if err := os.WriteFile(file, []byte(contents), 0o644); err != nil {
+				fmt.Println(err)
+				t.FailNow()
+			}
+		}
+		fake := &git.Fake{GitDirPath: t.TempDir(), RootPath: root, BranchName: "main", Config: gitConfig}
+		cfg, err := InitIn(fake, false)
+		return cfg, file, err
+	}
+	const fromFile = "<file>"
+	for _, c := range []struct {
+		name      string
+		file      string
+		gitConfig map[string]string
+		env       map[string]string
+		key       string
+		expected  string
+		source    string
+	}{
+		{"defaults", "", nil, nil, "header_max_length", "72", SourceDefault},
+		{"the config file", "header_max_length: 60", nil, nil, "header_max_length", "60", fromFile},
+		{
+			"git config over the config file",
+			"header_max_length: 60",
+			map[string]string{"cc.headerMaxLength": "55"},
+			nil,
+			"header_max_length", "55", "git config cc.headerMaxLength",
+		},
+		{
+			"the environment over git config",
+			"header_max_length: 60",
+			map[string]string{"cc.headerMaxLength": "55"},
+			map[string]string{"GIT_CC_HEADER_MAX_LENGTH": "50"},
+			"header_max_length", "50", "$GIT_CC_HEADER_MAX_LENGTH",
+		},
+		{
+			"the environment over the config file",
+			"enforce_header_max_length: true",
+			nil,
+			map[string]string{"GIT_CC_ENFORCE_HEADER_MAX_LENGTH": "no"},
+			"enforce_header_max_length", "false", "$GIT_CC_ENFORCE_HEADER_MAX_LENGTH",
+		},
+		{
+			"git config keys are case-insensitive",
+			"",
+			map[string]string{"CC.LOCALE": "ja"},
+			nil,
+			"locale", "ja", "git config cc.locale",
+		},
+		{
+			"presets from git config",
+			"",
+			map[string]string{"cc.preset": "conventional"},
+			nil,
+			"preset", "conventional", "git config cc.preset",
+		},
+		{
+			"presets from the environment",
+			"",
+			map[string]string{"cc.preset": "angular"},
+			map[string]string{"GIT_CC_PRESET": "conventional"},
+			"preset", "conventional", "$GIT_CC_PRESET",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, file, err := initIn(t, c.file, c.gitConfig, c.env)
+			if err != nil {
+				fmt.Println(err)
+				t.FailNow()
+			}
+			source := c.source
+			if source == fromFile {
+				source = file
+			}
+			for _, setting := range Settings {
+				if setting.Key != c.key {
+					continue
+				}
+				if actual := setting.Value(cfg); actual != c.expected {
+					fmt.Printf("expected %s to be %q, got %q\n", c.key, c.expected, actual)
+					t.Fail()
+				}
+			}
+			if actual := cfg.Source(c.key); actual != source {
+				fmt.Printf("expected %s to come from %q, got %q\n", c.key, source, actual)
+				t.Fail()
+			}
+		})
+	}
+	t.Run("presets set the commit types' source", func(t *testing.T) {
+		cfg, _, err := initIn(t, "", map[string]string{"cc.preset": "conventional"}, nil)
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if keys := keysOf(cfg.CommitTypes); strings.Join(keys, ",") != "feat,fix" {
+			fmt.Printf("expected the conventional types, got %q\n", keys)
+			t.Fail()
+		}
+		if source := cfg.Source("commit_types"); source != "preset conventional from git config cc.preset" {
+			fmt.Printf("unexpected source %q\n", source)
+			t.Fail()
+		}
+	})
+	for _, c := range []struct {
+		name      string
+		gitConfig map[string]string
+		env       map[string]string
+		problem   string
+	}{
+		{"invalid git config", map[string]string{"cc.headerMaxLength": "long"}, nil, "git config cc.headerMaxLength"},
+		{"invalid environment", nil, map[string]string{"GIT_CC_EDIT": "maybe"}, "$GIT_CC_EDIT"},
+		{"unknown preset", map[string]string{"cc.preset": "mine"}, nil, `unknown preset "mine"`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := initIn(t, "", c.gitConfig, c.env)
+			if err == nil || !strings.Contains(err.Error(), c.problem) {
+				fmt.Printf("expected an error like %q, got %v\n", c.problem, err)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package config
+
+// rendering a Cfg back into the schema of a config file
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// a top-level key of a config file, e.g. `header_max_length: 72`
+type Entry struct {
+	Key string
+	// the value as it would be written in a config file
+	Value interface{}
+	// where the value came from, if known; see Cfg.Source
+	Source string
+}
+
+// describe an ordered map as a list of single-key maps, which keeps its order
+// in any format. `details` returns any table of details for an entry.
+func toEntryList(om *OrderedMap, details func(name string) map[string]interface{}) []interface{} {
+	result := []interface{}{}
+	iter(om, func(name string, description string) {
+		if d := details(name); len(d) > 0 {
+			if description != "" {
+				d["description"] = description
+			}
+			result = append(result, map[string]interface{}{name: d})
+		} else {
+			result = append(result, map[string]interface{}{name: description})
+		}
+	})
+	return result
+}
+
+func toStringList(items []string) []interface{} {
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	return result
+}
+
+func (cfg *Cfg) commitTypesValue() []interface{} {
+	return toEntryList(cfg.CommitTypes, func(name string) map[string]interface{} {
+		info, ok := cfg.TypeInfo[name]
+		if !ok {
+			return nil
+		}
+		d := map[string]interface{}{}
+		if info.Bump != "" {
+			d["bump"] = info.Bump
+		}
+		if info.Changelog != "" {
+			d["changelog"] = info.Changelog
+		}
+		if info.Hidden {
+			d["hidden"] = true
+		}
+		if len(info.Aliases) > 0 {
+			d["aliases"] = toStringList(info.Aliases)
+		}
+		if info.Help != "" {
+			d["help"] = info.Help
+		}
+		return d
+	})
+}
+
+func (cfg *Cfg) scopesValue() []interface{} {
+	return toEntryList(cfg.Scopes, func(name string) map[string]interface{} {
+		info, ok := cfg.ScopeInfo[name]
+		if !ok {
+			return nil
+		}
+		d := map[string]interface{}{}
+		if len(info.Aliases) > 0 {
+			d["aliases"] = toStringList(info.Aliases)
+		}
+		if info.Deprecated != "" {
+			d["deprecated"] = info.Deprecated
+		}
+		return d
+	})
+}
+
+func rulesValue(rules *Rules) map[string]interface{} {
+	result := map[string]interface{}{}
+	if rules == nil {
+		return result
+	}
+	for pair := rules.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Applicable == "" {
+			result[pair.Key] = pair.Value.Level.String()
+			continue
+		}
+		rule := []interface{}{pair.Value.Level.String(), pair.Value.Applicable}
+		if pair.Value.Value != nil {
+			rule = append(rule, pair.Value.Value)
+		}
+		result[pair.Key] = rule
+	}
+	return result
+}
+
+func (cfg *Cfg) requiredFootersValue() map[string]interface{} {
+	result := map[string]interface{}{}
+	for commitType, footers := range cfg.RequiredFooters {
+		items := []interface{}{}
+		for _, footer := range footers {
+			item := map[string]interface{}{"token": footer.Token, "prompt": footer.Prompt}
+			if footer.Pattern != nil {
+				item["pattern"] = footer.Pattern.String()
+			}
+			items = append(items, item)
+		}
+		result[commitType] = items
+	}
+	return result
+}
+
+// the effective configuration, in the order and schema of a config file.
+// Optional settings that aren't set are left out.
+func (cfg *Cfg) Entries() []Entry {
+	entries := []Entry{
+		{Key: "config_file", Value: cfg.ConfigFile},
+		{Key: "preset", Value: cfg.Preset},
+		{Key: "commit_types", Value: cfg.commitTypesValue()},
+		{Key: "scopes", Value: cfg.scopesValue()},
+		{Key: "header_max_length", Value: cfg.HeaderMaxLength},
+		{Key: "enforce_header_max_length", Value: cfg.EnforceMaxLength},
+		{Key: "rules", Value: rulesValue(cfg.Rules)},
+	}
+	if cfg.BranchPattern != nil {
+		entries = append(entries, Entry{Key: "branch_pattern", Value: cfg.BranchPattern.String()})
+	}
+	entries = append(entries, Entry{Key: "ticket_footer", Value: cfg.TicketFooter})
+	if cfg.TicketHeaderFormat != "" {
+		entries = append(entries, Entry{Key: "ticket_header_format", Value: cfg.TicketHeaderFormat})
+	}
+	if len(cfg.RequiredFooters) > 0 {
+		entries = append(entries, Entry{Key: "required_footers", Value: cfg.requiredFootersValue()})
+	}
+	if len(cfg.Branches) > 0 {
+		entries = append(entries, Entry{Key: "branches", Value: cfg.branchesValue()})
+	}
+	if cfg.Locale != "" {
+		entries = append(entries, Entry{Key: "locale", Value: cfg.Locale})
+	}
+	for _, entry := range [...]Entry{
+		{Key: "commit_url_format", Value: cfg.CommitURLFormat},
+		{Key: "issue_url_format", Value: cfg.IssueURLFormat},
+		{Key: "compare_url_format", Value: cfg.CompareURLFormat},
+	} {
+		if entry.Value != "" {
+			entries = append(entries, entry)
+		}
+	}
+	if len(cfg.ProtectedRefs) > 0 {
+		entries = append(entries, Entry{Key: "protected_refs", Value: toStringList(cfg.ProtectedRefs)})
+	}
+	entries = append(entries, Entry{Key: "edit", Value: cfg.Edit})
+	for i := range entries {
+		entries[i].Source = cfg.Source(entries[i].Key)
+	}
+	return entries
+}
+
+// render entries as a yaml, toml, or json document. Sources are written as
+// comments, or as {"value", "source"} objects in json.
+func RenderEntries(entries []Entry, format string) (string, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, entry := range entries {
+		value := entry.Value
+		if format == "json" && entry.Source != "" {
+			value = map[string]interface{}{"value": entry.Value, "source": entry.Source}
+		}
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: entry.Key}
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(value); err != nil {
+			return "", fmt.Errorf("%s: %w", entry.Key, err)
+		}
+		flowShortSequences(valueNode)
+		if entry.Source != "" {
+			// comments on a key with an inline value get lost
+			if valueNode.Kind == yaml.ScalarNode || valueNode.Style == yaml.FlowStyle || len(valueNode.Content) == 0 {
+				valueNode.LineComment = "from " + entry.Source
+			} else {
+				keyNode.LineComment = "from " + entry.Source
+			}
+		}
+		root.Content = append(root.Content, keyNode, valueNode)
+	}
+	return RenderDocument(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, format)
+}
+
+// write short lists of scalars inline, e.g. `[error, never, 72]`
+func flowShortSequences(node *yaml.Node) {
+	for _, child := range node.Content {
+		flowShortSequences(child)
+	}
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, child := range node.Content {
+		if child.Kind == yaml.MappingNode || (child.Kind == yaml.SequenceNode && child.Style != yaml.FlowStyle) {
+			return
+		}
+	}
+	node.Style = yaml.FlowStyle
+}
+
+var bareTomlKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// encode a single scalar using the toml library's quoting rules
+func tomlScalar(value interface{}) (string, error) {
+	buf := bytes.Buffer{}
+	if err := toml.NewEncoder(&buf).Encode(map[string]interface{}{"v": value}); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(buf.String(), "v = "), "\n"), nil
+}
+
+func tomlKey(key string) (string, error) {
+	if bareTomlKey.MatchString(key) {
+		return key, nil
+	}
+	return tomlScalar(key)
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/skalt/git-cc/internal/i18n"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// how a commit type affects the next semantic version
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+	BumpNone  = "none"
+)
+
+// optional details about a commit type beyond its short description, e.g.
+//
+//	commit_types:
+//	  - feat:
+//	      description: adds a new feature
+//	      bump: minor
+//	      changelog: Features
+//	      aliases: [feature]
+//	      help: a longer explanation shown in the type selector
+type CommitTypeInfo struct {
+	// one of "major", "minor", "patch", or "none"
+	Bump string
+	// the changelog section listing commits of this type
+	Changelog string
+	// whether to leave commits of this type out of changelogs
+	Hidden bool
+	// other names that mean the same type, e.g. "feature" for "feat"
+	Aliases []string
+	// a longer explanation of when to use this type
+	Help string
+}
+
+// the details of the angular commit types, following conventional-changelog's
+// defaults. Types not listed here don't bump the version and are hidden from
+// changelogs.
+func angularCommitTypeInfo() map[string]CommitTypeInfo {
+	return map[string]CommitTypeInfo{
+		"feat":     {Bump: BumpMinor, Changelog: "Features"},
+		"fix":      {Bump: BumpPatch, Changelog: "Bug Fixes"},
+		"perf":     {Bump: BumpPatch, Changelog: "Performance Improvements"},
+		"revert":   {Bump: BumpNone, Changelog: "Reverts"},
+		"docs":     {Bump: BumpNone, Changelog: "Documentation", Hidden: true},
+		"style":    {Bump: BumpNone, Changelog: "Styles", Hidden: true},
+		"refactor": {Bump: BumpNone, Changelog: "Code Refactoring", Hidden: true},
+		"test":     {Bump: BumpNone, Changelog: "Tests", Hidden: true},
+		"build":    {Bump: BumpNone, Changelog: "Build System", Hidden: true},
+		"ci":       {Bump: BumpNone, Changelog: "Continuous Integration", Hidden: true},
+		"chore":    {Bump: BumpNone, Changelog: "Miscellaneous Chores", Hidden: true},
+	}
+}
+
+// translate the built-in descriptions of the preset's commit types
+func translateTypes(types *OrderedMap) *OrderedMap {
+	result := orderedmap.New[string, string]()
+	iter(types, func(name string, description string) {
+		if translated := i18n.T("type." + name); translated != "type."+name {
+			description = translated
+		}
+		result.Set(name, description)
+	})
+	return result
+}
+
+// look up the details of a commit type, falling back to the angular defaults
+// for well-known type names.
+func (cfg *Cfg) TypeInfoOf(commitType string) CommitTypeInfo {
+	if info, ok := cfg.TypeInfo[commitType]; ok {
+		return info
+	}
+	if info, ok := angularCommitTypeInfo()[commitType]; ok {
+		return info
+	}
+	return CommitTypeInfo{Bump: BumpNone, Hidden: true}
+}
+
+// resolve an alias to the name of the commit type it stands for. Unknown
+// types are returned unchanged.
+func (cfg *Cfg) CanonicalType(commitType string) string {
+	if _, known := cfg.CommitTypes.Get(commitType); known {
+		return commitType
+	}
+	for name, info := range cfg.TypeInfo {
+		if slices.Contains(info.Aliases, commitType) {
+			return name
+		}
+	}
+	return commitType
+}
+
+func toStringSlice(raw interface{}) ([]string, error) {
+	switch r := raw.(type) {
+	case string:
+		return []string{r}, nil
+	case []interface{}:
+		result := make([]string, 0, len(r))
+		for _, item := range r {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string, got %+v", item)
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected a list of strings, got %+v", raw)
+	}
+}
+
+// parse each commit type's optional details. Unset details default to the
+// angular defaults for well-known type names.
+func toCommitTypeInfo(types *OrderedMap, details map[string]map[string]interface{}) (map[string]CommitTypeInfo, error) {
+	result := make(map[string]CommitTypeInfo, types.Len())
+	defaults := angularCommitTypeInfo()
+	aliased := map[string]string{}
+	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+		info, known := defaults[name]
+		if !known {
+			info = CommitTypeInfo{Bump: BumpNone, Hidden: true}
+		}
+		for key, value := range details[name] {
+			var ok bool
+			switch key {
+			case "description":
+				ok = true // already the value in `types`
+			case "bump":
+				info.Bump, ok = value.(string)
+				switch info.Bump {
+				case BumpMajor, BumpMinor, BumpPatch, BumpNone:
+				default:
+					return nil, fmt.Errorf("%s: unknown bump %+v; expected one of major, minor, patch, none", name, value)
+				}
+			case "changelog":
+				info.Changelog, ok = value.(string)
+			case "hidden":
+				info.Hidden, ok = value.(bool)
+			case "help":
+				info.Help, ok = value.(string)
+			case "aliases":
+				var err error
+				if info.Aliases, err = toStringSlice(value); err != nil {
+					return nil, fmt.Errorf("%s.aliases: %w", name, err)
+				}
+				ok = true
+			default:
+				return nil, fmt.Errorf("%s: unknown key %q", name, key)
+			}
+			if !ok {
+				return nil, fmt.Errorf("%s: unexpected value for %s: %+v", name, key, value)
+			}
+		}
+		for _, alias := range info.Aliases {
+			if _, present := types.Get(alias); present {
+				return nil, fmt.Errorf("%s: alias %q is also a commit type", name, alias)
+			}
+			if other, present := aliased[alias]; present {
+				return nil, fmt.Errorf("%s: alias %q is also an alias of %s", name, alias, other)
+			}
+			aliased[alias] = name
+		}
+		result[name] = info
+	}
+	return result, nil
+}
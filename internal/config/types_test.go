@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCommitTypes(t *testing.T) {
+	cfg, err := parseYamlCfg(t, `
+commit_types:
+  - feat:
+      description: adds a feature
+      bump: major
+      changelog: New
+      aliases: [feature, feature-add]
+  - fix: fixes a bug
+  - wip:
+      description: work in progress
+      help: squash these before merging
+  - docs:
+      description: documents
+      hidden: false
+`)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	t.Run("details", func(t *testing.T) {
+		for name, expected := range map[string]CommitTypeInfo{
+			"feat": {Bump: BumpMajor, Changelog: "New", Aliases: []string{"feature", "feature-add"}},
+			// well-known types default to the angular details
+			"fix":  {Bump: BumpPatch, Changelog: "Bug Fixes"},
+			"wip":  {Bump: BumpNone, Hidden: true, Help: "squash these before merging"},
+			"docs": {Bump: BumpNone, Changelog: "Documentation"},
+			// unlisted types fall back to the angular details, if any
+			"perf":    {Bump: BumpPatch, Changelog: "Performance Improvements"},
+			"unknown": {Bump: BumpNone, Hidden: true},
+		} {
+			actual := cfg.TypeInfoOf(name)
+			if actual.Bump != expected.Bump || actual.Changelog != expected.Changelog || actual.Hidden != expected.Hidden ||
+				actual.Help != expected.Help || !slices.Equal(actual.Aliases, expected.Aliases) {
+				fmt.Printf("%s: expected %+v, got %+v\n", name, expected, actual)
+				t.Fail()
+			}
+		}
+	})
+	t.Run("aliases", func(t *testing.T) {
+		for commitType, expected := range map[string]string{
+			"feat": "feat", "feature": "feat", "feature-add": "feat", "fix": "fix", "fixes": "fixes", "": "",
+		} {
+			if actual := cfg.CanonicalType(commitType); actual != expected {
+				fmt.Printf("%q: expected %q, got %q\n", commitType, expected, actual)
+				t.Fail()
+			}
+		}
+	})
+	for _, c := range []struct {
+		name, types, problem string
+	}{
+		{"unknown bump", "[{feat: {bump: huge}}]", "feat: unknown bump huge"},
+		{"non-string bump", "[{feat: {bump: 1}}]", "feat: unknown bump 1"},
+		{"non-string changelog", "[{feat: {changelog: 1}}]", "feat: unexpected value for changelog"},
+		{"non-boolean hidden", "[{feat: {hidden: sometimes}}]", "feat: unexpected value for hidden"},
+		{"unknown key", "[{feat: {section: Features}}]", `feat: unknown key "section"`},
+		{"invalid aliases", "[{feat: {aliases: [1]}}]", "feat.aliases"},
+		{"aliases that are types", "[{feat: {aliases: [fix]}}, fix]", `alias "fix" is also a commit type`},
+		{"shared aliases", "[{feat: {aliases: [f]}}, {fix: {aliases: [f]}}]", `alias "f" is also an alias of feat`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseYamlCfg(t, "commit_types: "+c.types)
+			if err == nil || !strings.Contains(err.Error(), c.problem) {
+				fmt.Printf("expected an error like %q, got %v\n", c.problem, err)
+				t.Fail()
+			}
+		})
+	}
+}
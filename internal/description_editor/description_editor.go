@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
@@ -14,11 +15,10 @@ import (
 	"github.com/muesli/termenv"
 	"github.com/skalt/git-cc/internal/config"
 	"github.com/skalt/git-cc/internal/helpbar"
+	"github.com/skalt/git-cc/internal/i18n"
 	"github.com/skalt/git-cc/internal/utils"
 )
 
-const prePrompt = "A short description of the changes:"
-
 type Model struct {
 	width       int             // TODO: drop in favor of input.Width()
 	input       textinput.Model // TODO: make input a pointer
@@ -30,6 +30,10 @@ type Model struct {
 func (m Model) SetPrefix(prefix string) Model {
 	m.prefix = prefix
 	m.input.Prompt = prefix
+	if m.input.CharLimit > 0 {
+		// the prefix counts towards the length of the header
+		m.input.CharLimit = max(1, m.lengthLimit-utf8.RuneCountInString(prefix))
+	}
 	return m
 }
 func (m Model) SetErr(err error) Model {
@@ -49,7 +53,7 @@ func NewModel(lengthLimit int, value string, enforced bool) Model {
 	input.SetValue(value)
 	input.SetCursor(len(value))
 	// input.Cursor = len(value)
-	input.Prompt = config.Faint(prePrompt)
+	input.Prompt = config.Faint(i18n.T("prompt.description"))
 	if enforced {
 		input.CharLimit = lengthLimit
 	}
@@ -58,9 +62,9 @@ func NewModel(lengthLimit int, value string, enforced bool) Model {
 		lengthLimit: lengthLimit,
 		input:       input,
 		helpBar: helpbar.NewModel(
-			config.HelpSubmit,
-			config.HelpBack,
-			config.HelpCancel,
+			i18n.T("help.submit"),
+			i18n.T("help.back"),
+			i18n.T("help.cancel"),
 		),
 	}
 }
@@ -114,7 +118,7 @@ func must[T any](t T, err error) T {
 }
 
 func (m Model) Render(s io.StringWriter) {
-	_ = must(s.WriteString(wordwrap.String(config.Faint(prePrompt), m.width)))
+	_ = must(s.WriteString(wordwrap.String(config.Faint(i18n.T("prompt.description")), m.width)))
 	_ = must(s.WriteString("\n\n"))
 	_ = must(s.WriteString(m.input.View()))
 	_ = must(s.WriteString("\n\n"))
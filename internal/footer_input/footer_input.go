@@ -0,0 +1,105 @@
+package footer_input
+
+// prompts for each required footer a commit is missing, one at a time
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/helpbar"
+	"github.com/skalt/git-cc/internal/i18n"
+	"github.com/skalt/git-cc/internal/utils"
+)
+
+type Model struct {
+	input    textinput.Model
+	helpBar  helpbar.Model
+	required []config.RequiredFooter
+	// the index of the required footer being prompted for
+	current int
+	// completed footers, e.g. "Refs: PAY-123"
+	values []string
+}
+
+func NewModel() Model {
+	input := textinput.New()
+	input.Focus()
+	return Model{
+		input: input,
+		helpBar: helpbar.NewModel(
+			i18n.T("help.submit"), i18n.T("help.back"), i18n.T("help.cancel"),
+		),
+	}
+}
+
+// start prompting for the given footers, discarding any previous answers.
+func (m Model) Require(required []config.RequiredFooter) Model {
+	m.required = required
+	m.current = 0
+	m.values = nil
+	m.input.SetValue("")
+	m.input.Err = nil
+	return m
+}
+
+// whether every required footer has been filled in
+func (m Model) Done() bool {
+	return m.current >= len(m.required)
+}
+
+func (m Model) Value() string {
+	return strings.Join(m.values, "\n")
+}
+
+func (m Model) Render(s io.StringWriter) {
+	if m.Done() {
+		return
+	}
+	footer := m.required[m.current]
+	_ = utils.Must(s.WriteString(config.Faint(footer.Prompt)))
+	_ = utils.Must(s.WriteString("\n\n"))
+	m.input.Prompt = footer.Token + ": "
+	_ = utils.Must(s.WriteString(m.input.View()))
+	if m.input.Err != nil {
+		_ = utils.Must(s.WriteString("\n"))
+		_ = utils.Must(s.WriteString(m.input.Err.Error()))
+	}
+	_ = utils.Must(s.WriteString("\n\n"))
+	m.helpBar.Render(s)
+	_ = utils.Must(s.WriteString("\n"))
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.Code {
+		case tea.KeyEnter, tea.KeyTab:
+			if m.Done() {
+				return m, cmd
+			}
+			footer := m.required[m.current]
+			value := strings.TrimSpace(m.input.Value())
+			if !footer.Accepts(value) {
+				m.input.Err = fmt.Errorf(i18n.T("footer.must_match"), footer.Token, footer.Pattern)
+				if footer.Pattern == nil {
+					m.input.Err = fmt.Errorf(i18n.T("footer.required"), footer.Token)
+				}
+				return m, cmd
+			}
+			m.values = append(m.values, footer.Token+": "+value)
+			m.current++
+			m.input.SetValue("")
+			m.input.Err = nil
+			return m, cmd
+		}
+	case tea.WindowSizeMsg:
+		m.helpBar, _ = m.helpBar.Update(msg)
+	}
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,365 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// the format `git log` prints commits in for Log: fields are separated by
+// ASCII unit separators and commits by record separators, which are unlikely
+// to appear in commit messages
+const logFormat = "--format=%H%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%B%x1e"
+
+// a Repo that runs git in the current directory. What doesn't change while
+// git-cc runs, like the git directory or git config, is read once and cached.
+type ExecRepo struct {
+	located bool
+	gitDir  string
+	root    string
+	// why the git directory or the root couldn't be found, if they couldn't
+	gitDirErr, rootErr error
+
+	branchRead bool
+	branch     string
+	branchErr  error
+
+	// git config, keyed by normalizeKey
+	config map[string]string
+}
+
+var _ Repo = &ExecRepo{}
+
+func NewExecRepo() *ExecRepo {
+	return &ExecRepo{}
+}
+
+// find the git directory and the root of the work tree with one `git rev-parse`
+// where possible; bare repos have no work tree.
+func (r *ExecRepo) locate() {
+	if r.located {
+		return
+	}
+	r.located = true
+	out, err := Output("", "rev-parse", "--absolute-git-dir", "--show-toplevel")
+	if lines := strings.Split(strings.TrimRight(out, "\r\n"), "\n"); err == nil && len(lines) == 2 {
+		r.gitDir, r.root = lines[0], lines[1]
+		return
+	}
+	r.rootErr = err
+	out, r.gitDirErr = Output("", "rev-parse", "--absolute-git-dir")
+	r.gitDir = strings.TrimSpace(out)
+}
+
+func (r *ExecRepo) GitDir() (string, error) {
+	r.locate()
+	return r.gitDir, r.gitDirErr
+}
+
+func (r *ExecRepo) Root() (string, error) {
+	r.locate()
+	return r.root, r.rootErr
+}
+
+func (r *ExecRepo) Branch() (string, error) {
+	if r.branchRead {
+		return r.branch, r.branchErr
+	}
+	r.branchRead = true
+	// unlike `rev-parse --abbrev-ref HEAD`, this works on branches without any
+	// commits yet
+	out, err := Output("", "symbolic-ref", "--quiet", "--short", "HEAD")
+	r.branch = strings.TrimSpace(out)
+	if err != nil {
+		if _, r.branchErr = r.GitDir(); r.branchErr == nil {
+			r.branch = "HEAD" // detached
+		}
+	}
+	return r.branch, r.branchErr
+}
+
+func (r *ExecRepo) StagedFiles() ([]string, error) {
+	out, err := Output("", "diff", "--name-only", "--cached", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(out, func(c rune) bool { return c == 0 }), nil
+}
+
+// parse the output of `git diff --numstat -z`. Each file is listed like
+// `added\tdeleted\tpath\0`, or `added\tdeleted\t\0from\0path\0` if it was renamed;
+// binary files' counts are "-".
+func parseNumstat(out string) ([]FileStat, error) {
+	stats := []FileStat{}
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "" {
+			continue
+		}
+		counts := strings.SplitN(fields[i], "\t", 3)
+		if len(counts) != 3 {
+			return nil, fmt.Errorf("unexpected output from git diff --numstat: %q", fields[i])
+		}
+		stat := FileStat{Path: counts[2], Binary: counts[0] == "-"}
+		if stat.Path == "" && i+2 < len(fields) {
+			stat.From, stat.Path = fields[i+1], fields[i+2]
+			i += 2
+		}
+		if !stat.Binary {
+			var err error
+			if stat.Added, err = strconv.Atoi(counts[0]); err == nil {
+				stat.Deleted, err = strconv.Atoi(counts[1])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unexpected output from git diff --numstat: %q", fields[i])
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func (r *ExecRepo) StagedStats() ([]FileStat, error) {
+	out, err := Output("", "diff", "--cached", "--numstat", "-z", "-M")
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(out)
+}
+
+func (r *ExecRepo) HeadMessage() (string, error) {
+	return headMessage(r.Log("-1", "HEAD", "--"))
+}
+
+// parse a commit printed in logFormat
+func parseLogRecord(record string) (Commit, error) {
+	fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 6)
+	if len(fields) != 6 {
+		return Commit{}, fmt.Errorf("unexpected output from git log: %q", record)
+	}
+	return Commit{
+		SHA:     fields[0],
+		Parents: strings.Fields(fields[1]),
+		Author:  fields[2],
+		Email:   fields[3],
+		Date:    fields[4],
+		Message: strings.TrimRight(fields[5], "\n"),
+	}, nil
+}
+
+func (r *ExecRepo) Log(args ...string) iter.Seq2[Commit, error] {
+	args = append([]string{"log", logFormat}, args...)
+	return func(yield func(Commit, error) bool) {
+		var stderr bytes.Buffer
+		process := exec.Command("git", args...)
+		process.Stderr = &stderr
+		stdout, err := process.StdoutPipe()
+		if err == nil {
+			err = process.Start()
+		}
+		if err != nil {
+			yield(Commit{}, err)
+			return
+		}
+		// commits are read as git prints them, so stopping early doesn't wait
+		// for the rest of the history
+		defer func() {
+			_ = process.Process.Kill()
+			_ = process.Wait()
+		}()
+		reader := bufio.NewReader(stdout)
+		for {
+			record, err := reader.ReadString('\x1e')
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				yield(Commit{}, err)
+				return
+			}
+			commit, err := parseLogRecord(strings.TrimSuffix(record, "\x1e"))
+			if !yield(commit, err) || err != nil {
+				return
+			}
+		}
+		if err := process.Wait(); err != nil {
+			yield(Commit{}, fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String())))
+		}
+	}
+}
+
+// parse the output of `git config --null --list`
+func parseConfigList(out string) map[string]string {
+	values := map[string]string{}
+	for _, entry := range strings.Split(out, "\x00") {
+		if entry == "" {
+			continue
+		}
+		// a key without a value, like `[core] bare`, means true
+		key, value, found := strings.Cut(entry, "\n")
+		if !found {
+			value = "true"
+		}
+		values[normalizeKey(key)] = value // later values override earlier ones
+	}
+	return values
+}
+
+// read every git config key at once, since each git process adds to startup
+// time
+func (r *ExecRepo) readConfig() map[string]string {
+	if r.config == nil {
+		out, err := Output("", "config", "--null", "--list")
+		if err != nil {
+			out = ""
+		}
+		r.config = parseConfigList(out)
+	}
+	return r.config
+}
+
+func (r *ExecRepo) ConfigGet(key string) (string, bool) {
+	value, ok := r.readConfig()[normalizeKey(key)]
+	return value, ok
+}
+
+func (r *ExecRepo) ConfigPrefixed(prefix string) map[string]string {
+	values := map[string]string{}
+	prefix = strings.ToLower(prefix)
+	for key, value := range r.readConfig() {
+		if strings.HasPrefix(strings.ToLower(key), prefix) {
+			values[strings.ToLower(key)] = value
+		}
+	}
+	return values
+}
+
+func (r *ExecRepo) Commit(message string, args ...string) error {
+	args = append([]string{"commit", "--message", message}, args...)
+	process := exec.Command("git", args...)
+	process.Stdin = os.Stdin
+	process.Stdout = os.Stdout
+	process.Stderr = os.Stderr
+	return process.Run()
+}
+
+func (r *ExecRepo) ResolveRef(rev string) (string, error) {
+	out, err := Output("", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (r *ExecRepo) IsAncestor(commit string, ref string) bool {
+	_, err := Output("", "merge-base", "--is-ancestor", commit, ref)
+	return err == nil
+}
+
+func (r *ExecRepo) ListRefs(patterns ...string) ([]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname:short)"}
+	for _, pattern := range patterns {
+		args = append(args, "refs/heads/"+pattern, "refs/remotes/"+pattern, "refs/tags/"+pattern)
+	}
+	out, err := Output("", args...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+func (r *ExecRepo) ChangedFiles(commit string) ([]string, error) {
+	out, err := Output("", "diff-tree", "-z", "--no-commit-id", "--name-only", "-r", "--root", commit)
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(out, func(c rune) bool { return c == 0 }), nil
+}
+
+func (r *ExecRepo) CatCommit(commit string) (string, error) {
+	return Output("", "cat-file", "commit", commit)
+}
+
+func (r *ExecRepo) WriteCommit(raw string) (string, error) {
+	out, err := Output(raw, "hash-object", "-t", "commit", "-w", "--stdin")
+	return strings.TrimSpace(out), err
+}
+
+func (r *ExecRepo) UpdateRef(ref string, new string, old string, reason string) error {
+	_, err := Output("", "update-ref", "-m", reason, ref, new, old)
+	return err
+}
+
+func (r *ExecRepo) Tags(ref string, match string) ([]string, error) {
+	out, err := Output("", "tag", "--merged", ref, "--list", match)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+func (r *ExecRepo) NearestTag(ref string, match string, exclude string) (string, error) {
+	args := []string{"describe", "--tags", "--abbrev=0", "--match", match}
+	if exclude != "" {
+		args = append(args, "--exclude", exclude)
+	}
+	out, err := Output("", append(args, ref)...)
+	return strings.TrimSpace(out), err
+}
+
+func (r *ExecRepo) TagAt(ref string, match string) (string, error) {
+	out, err := Output("", "describe", "--tags", "--exact-match", "--match", match, ref)
+	return strings.TrimSpace(out), err
+}
+
+func (r *ExecRepo) CreateTag(name string, ref string, message string) error {
+	_, err := Output("", "tag", "--annotate", "--message", message, name, ref)
+	return err
+}
+
+func (r *ExecRepo) RemoteURL(name string) (string, error) {
+	out, err := Output("", "remote", "get-url", name)
+	return strings.TrimSpace(out), err
+}
+
+func (r *ExecRepo) GitPath(name string) (string, error) {
+	out, err := Output("", "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	// the path is relative to the current directory unless it's elsewhere
+	return filepath.Abs(strings.TrimSpace(out))
+}
+
+func (r *ExecRepo) Var(name string) (string, error) {
+	out, err := Output("", "var", name)
+	return strings.TrimRight(out, " \t\r\n"), err
+}
+
+// an *exec.Cmd that fits Command
+type execCommand struct{ *exec.Cmd }
+
+func (c execCommand) SetStdin(r io.Reader)  { c.Stdin = r }
+func (c execCommand) SetStdout(w io.Writer) { c.Stdout = w }
+func (c execCommand) SetStderr(w io.Writer) { c.Stderr = w }
+
+func (r *ExecRepo) PageStagedDiff(paths ...string) Command {
+	args := []string{"--paginate", "diff", "--cached", "-M", "--"}
+	for _, path := range paths {
+		// pathspecs are otherwise relative to the current directory
+		args = append(args, ":(top)"+path)
+	}
+	process := exec.Command("git", args...)
+	if os.Getenv("LESS") == "" {
+		// git's default for less is FRX, but -F would quit short diffs at once
+		process.Env = append(os.Environ(), "LESS=RX")
+	}
+	return execCommand{process}
+}
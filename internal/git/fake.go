@@ -0,0 +1,613 @@
+package git
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// a commit made through a Fake
+type FakeCommit struct {
+	Message string
+	Args    []string
+}
+
+// a Repo scripted by its fields, for tests. Commits it makes are recorded
+// rather than written anywhere.
+type Fake struct {
+	GitDirPath string
+	RootPath   string
+	// "" means HEAD is detached
+	BranchName string
+	Staged     []string
+	// the counts of the staged changes; if unset, each staged file has none
+	Stats []FileStat
+	// the history, newest first, like `git log`. A commit without parents
+	// follows the next one, as in a linear history, unless it's the last.
+	History []Commit
+	// full ref names like refs/heads/main or refs/tags/v1.0.0 and the hashes
+	// they point to
+	Refs map[string]string
+	// the paths each commit changes, by hash
+	Changes map[string][]string
+	// commit objects by hash, as `git cat-file commit` prints them; commits in
+	// History without one get a minimal object
+	Objects map[string]string
+	// git config, keyed like `cc.preset`
+	Config map[string]string
+	// git logical variables like GIT_EDITOR
+	Vars map[string]string
+	// the URLs of remotes by name
+	Remotes map[string]string
+	// if set, every method that can fail fails with this
+	Err error
+	// what Commit was called with, in order
+	Committed []FakeCommit
+	// what PageStagedDiff was called with, in order
+	Paged [][]string
+
+	// commits written by WriteCommit, in order
+	written []Commit
+}
+
+var _ Repo = &Fake{}
+
+func (f *Fake) GitDir() (string, error) {
+	return f.GitDirPath, f.Err
+}
+
+func (f *Fake) Root() (string, error) {
+	return f.RootPath, f.Err
+}
+
+func (f *Fake) Branch() (string, error) {
+	if f.BranchName == "" {
+		return "HEAD", f.Err
+	}
+	return f.BranchName, f.Err
+}
+
+func (f *Fake) StagedFiles() ([]string, error) {
+	return f.Staged, f.Err
+}
+
+func (f *Fake) StagedStats() ([]FileStat, error) {
+	if f.Stats != nil {
+		return f.Stats, f.Err
+	}
+	stats := []FileStat{}
+	for _, path := range f.Staged {
+		stats = append(stats, FileStat{Path: path})
+	}
+	return stats, f.Err
+}
+
+func (f *Fake) HeadMessage() (string, error) {
+	return headMessage(f.Log("-1"))
+}
+
+// every commit the fake knows of: the history, then any written since
+func (f *Fake) commits() []Commit {
+	commits := slices.Clone(f.History)
+	for _, c := range f.written {
+		if !slices.ContainsFunc(f.History, func(h Commit) bool { return h.SHA == c.SHA }) {
+			commits = append(commits, c)
+		}
+	}
+	return commits
+}
+
+func (f *Fake) lookup(sha string) (Commit, bool) {
+	commits := f.commits()
+	i := slices.IndexFunc(commits, func(c Commit) bool { return c.SHA == sha })
+	if i < 0 {
+		return Commit{}, false
+	}
+	return commits[i], true
+}
+
+func (f *Fake) parents(sha string) []string {
+	if i := slices.IndexFunc(f.History, func(c Commit) bool { return c.SHA == sha }); i >= 0 {
+		if f.History[i].Parents == nil && i+1 < len(f.History) {
+			return []string{f.History[i+1].SHA}
+		}
+		return f.History[i].Parents
+	}
+	c, _ := f.lookup(sha)
+	return c.Parents
+}
+
+// the hashes reachable from some commits, nearest first
+func (f *Fake) reachable(shas ...string) []string {
+	seen := []string{}
+	queue := slices.Clone(shas)
+	for len(queue) > 0 {
+		sha := queue[0]
+		queue = queue[1:]
+		if slices.Contains(seen, sha) {
+			continue
+		}
+		seen = append(seen, sha)
+		queue = append(queue, f.parents(sha)...)
+	}
+	return seen
+}
+
+// matches revisions like `main~2` or `v1.0.0^2`
+var revSuffix = regexp.MustCompile(`^(.*?)((?:[~^]\d*)*)$`)
+
+// the hash of the commit a revision names
+func (f *Fake) resolve(rev string) (string, error) {
+	match := revSuffix.FindStringSubmatch(strings.TrimSuffix(rev, "^{commit}"))
+	name, suffix := match[1], match[2]
+	sha := ""
+	if name == "HEAD" || name == "@" {
+		if len(f.History) == 0 {
+			return "", errors.New("HEAD has no commits")
+		}
+		sha = f.History[0].SHA
+	}
+	for _, prefix := range []string{"", "refs/", "refs/tags/", "refs/heads/", "refs/remotes/"} {
+		if target, ok := f.Refs[prefix+name]; sha == "" && ok {
+			sha = target
+		}
+	}
+	if sha == "" && name != "" {
+		for _, c := range f.commits() {
+			if c.SHA == name || (len(name) >= 4 && strings.HasPrefix(c.SHA, name)) {
+				sha = c.SHA
+				break
+			}
+		}
+	}
+	if sha == "" {
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+	for _, step := range regexp.MustCompile(`[~^]\d*`).FindAllString(suffix, -1) {
+		n := 1
+		if step[1:] != "" {
+			n, _ = strconv.Atoi(step[1:])
+		}
+		if step[0] == '~' {
+			for ; n > 0 && sha != ""; n-- {
+				sha = first(f.parents(sha))
+			}
+		} else if n > 0 {
+			parents := f.parents(sha)
+			sha = ""
+			if n <= len(parents) {
+				sha = parents[n-1]
+			}
+		}
+		if sha == "" {
+			return "", fmt.Errorf("unknown revision %s", rev)
+		}
+	}
+	return sha, nil
+}
+
+func first(shas []string) string {
+	if len(shas) == 0 {
+		return ""
+	}
+	return shas[0]
+}
+
+// whether a commit changes a file under any of the paths, relative to the
+// root like `:(top)dir` or not
+func (f *Fake) touches(sha string, paths []string) bool {
+	for _, file := range f.Changes[sha] {
+		for _, p := range paths {
+			p = strings.TrimSuffix(strings.TrimPrefix(p, ":(top)"), "/")
+			if p == "" || p == "." || file == p || strings.HasPrefix(file, p+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// list the commits reachable from revisions like `HEAD`, `a..b`, or `^a`,
+// newest first. Only `-<n>`, `-n <n>`, `--reverse`, and paths after `--` are
+// understood besides revisions.
+func (f *Fake) Log(args ...string) iter.Seq2[Commit, error] {
+	return func(yield func(Commit, error) bool) {
+		if f.Err != nil {
+			yield(Commit{}, f.Err)
+			return
+		}
+		limit := -1
+		reverse := false
+		include, exclude, paths := []string{}, []string{}, []string{}
+		for i := 0; i < len(args); i++ {
+			arg := args[i]
+			if arg == "--" {
+				paths = args[i+1:]
+				break
+			} else if arg == "--reverse" {
+				reverse = true
+			} else if arg == "-n" && i+1 < len(args) {
+				limit, _ = strconv.Atoi(args[i+1])
+				i++
+			} else if n, err := strconv.Atoi(arg); err == nil && n < 0 {
+				limit = -n
+			} else if from, to, found := strings.Cut(arg, ".."); found {
+				exclude = append(exclude, orHead(from))
+				include = append(include, orHead(to))
+			} else if rev, found := strings.CutPrefix(arg, "^"); found {
+				exclude = append(exclude, rev)
+			} else {
+				include = append(include, arg)
+			}
+		}
+		if len(include) == 0 {
+			include = append(include, "HEAD")
+		}
+		resolve := func(revs []string) ([]string, error) {
+			shas := []string{}
+			for _, rev := range revs {
+				sha, err := f.resolve(rev)
+				if err != nil {
+					return nil, err
+				}
+				shas = append(shas, sha)
+			}
+			return shas, nil
+		}
+		included, err := resolve(include)
+		if err == nil {
+			exclude, err = resolve(exclude)
+		}
+		if err != nil {
+			yield(Commit{}, err)
+			return
+		}
+		reachable, excluded := f.reachable(included...), f.reachable(exclude...)
+		commits := []Commit{}
+		for _, c := range f.commits() {
+			if !slices.Contains(reachable, c.SHA) || slices.Contains(excluded, c.SHA) {
+				continue
+			}
+			if len(paths) > 0 && !f.touches(c.SHA, paths) {
+				continue
+			}
+			c.Parents = f.parents(c.SHA)
+			commits = append(commits, c)
+		}
+		// like git, limit the commits before reversing them
+		if limit >= 0 {
+			commits = commits[:min(limit, len(commits))]
+		}
+		if reverse {
+			slices.Reverse(commits)
+		}
+		for _, commit := range commits {
+			if !yield(commit, nil) {
+				return
+			}
+		}
+	}
+}
+
+// an end of a range like `..main`, where a missing end means HEAD
+func orHead(rev string) string {
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
+
+func (f *Fake) ConfigGet(key string) (string, bool) {
+	for k, value := range f.Config {
+		if normalizeKey(k) == normalizeKey(key) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func (f *Fake) ConfigPrefixed(prefix string) map[string]string {
+	values := map[string]string{}
+	for key, value := range f.Config {
+		if strings.HasPrefix(strings.ToLower(key), strings.ToLower(prefix)) {
+			values[strings.ToLower(key)] = value
+		}
+	}
+	return values
+}
+
+// record the commit, adding it to the history or, with --amend, replacing
+// HEAD with it
+func (f *Fake) Commit(message string, args ...string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	if len(f.Staged) == 0 && !slices.Contains(args, "--allow-empty") && !slices.Contains(args, "--amend") {
+		return errors.New("nothing added to commit")
+	}
+	f.Committed = append(f.Committed, FakeCommit{message, args})
+	commit := Commit{SHA: fmt.Sprintf("%x", sha1.Sum([]byte(message))), Message: message}
+	history := f.History
+	if slices.Contains(args, "--amend") && len(history) > 0 {
+		history = history[1:]
+	}
+	commit.Parents = []string{}
+	if len(history) > 0 {
+		commit.Parents = []string{history[0].SHA}
+	}
+	f.History = append([]Commit{commit}, history...)
+	f.Staged = nil
+	return nil
+}
+
+func (f *Fake) ResolveRef(rev string) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return f.resolve(rev)
+}
+
+func (f *Fake) IsAncestor(commit string, ref string) bool {
+	from, err := f.resolve(ref)
+	if err != nil {
+		return false
+	}
+	sha, err := f.resolve(commit)
+	return err == nil && slices.Contains(f.reachable(from), sha)
+}
+
+// the short name of a ref, like `main` for refs/heads/main
+func shortName(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/", "refs/tags/"} {
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			return name
+		}
+	}
+	return ref
+}
+
+func (f *Fake) ListRefs(patterns ...string) ([]string, error) {
+	names := []string{}
+	for ref := range f.Refs {
+		for _, pattern := range patterns {
+			for _, prefix := range []string{"refs/heads/", "refs/remotes/", "refs/tags/"} {
+				if ok, _ := path.Match(prefix+pattern, ref); ok && !slices.Contains(names, shortName(ref)) {
+					names = append(names, shortName(ref))
+				}
+			}
+		}
+	}
+	slices.Sort(names)
+	return names, f.Err
+}
+
+func (f *Fake) ChangedFiles(commit string) ([]string, error) {
+	sha, err := f.ResolveRef(commit)
+	if err != nil {
+		return nil, err
+	}
+	return f.Changes[sha], nil
+}
+
+// the `Name <email> timestamp zone` of a commit's author
+func signature(c Commit) string {
+	date, err := time.Parse(time.RFC3339, c.Date)
+	if err != nil {
+		date = time.Unix(0, 0).UTC()
+	}
+	return fmt.Sprintf("%s <%s> %d %s", c.Author, c.Email, date.Unix(), date.Format("-0700"))
+}
+
+func (f *Fake) CatCommit(commit string) (string, error) {
+	sha, err := f.ResolveRef(commit)
+	if err != nil {
+		return "", err
+	}
+	if raw, ok := f.Objects[sha]; ok {
+		return raw, nil
+	}
+	c, _ := f.lookup(sha)
+	// the tree of an empty directory
+	raw := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n"
+	for _, parent := range f.parents(sha) {
+		raw += "parent " + parent + "\n"
+	}
+	raw += "author " + signature(c) + "\n"
+	raw += "committer " + signature(c) + "\n"
+	return raw + "\n" + c.Message + "\n", nil
+}
+
+// matches signatures like `A U Thor <au@thor.example> 1700000000 +0100`
+var signaturePattern = regexp.MustCompile(`^(.*) <(.*)> (\d+) ([+-]\d{4})$`)
+
+// store a commit object, adding the commit it describes to the commits the
+// fake knows of
+func (f *Fake) WriteCommit(raw string) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	header, message, found := strings.Cut(raw, "\n\n")
+	if !found {
+		return "", errors.New("fatal: corrupt commit")
+	}
+	commit := Commit{
+		SHA:     fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("commit %d\x00%s", len(raw), raw)))),
+		Parents: []string{},
+		Message: strings.TrimRight(message, "\n"),
+	}
+	for _, line := range strings.Split(header, "\n") {
+		if parent, ok := strings.CutPrefix(line, "parent "); ok {
+			commit.Parents = append(commit.Parents, parent)
+		} else if author, ok := strings.CutPrefix(line, "author "); ok {
+			match := signaturePattern.FindStringSubmatch(author)
+			if match == nil {
+				return "", fmt.Errorf("fatal: corrupt author line %q", author)
+			}
+			seconds, _ := strconv.ParseInt(match[3], 10, 64)
+			zone, _ := time.Parse("-0700", match[4])
+			commit.Author, commit.Email = match[1], match[2]
+			commit.Date = time.Unix(seconds, 0).In(zone.Location()).Format(time.RFC3339)
+		}
+	}
+	if f.Objects == nil {
+		f.Objects = map[string]string{}
+	}
+	if _, exists := f.lookup(commit.SHA); !exists {
+		f.written = append(f.written, commit)
+	}
+	f.Objects[commit.SHA] = raw
+	return commit.SHA, nil
+}
+
+// move a ref, failing like git if something else moved it first. Moving HEAD
+// rewrites the history to end at the new commit.
+func (f *Fake) UpdateRef(ref string, new string, old string, reason string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	current := f.Refs[ref]
+	if ref == "HEAD" && len(f.History) > 0 {
+		current = f.History[0].SHA
+	}
+	if current != old {
+		return fmt.Errorf("cannot lock ref '%s': is at %s but expected %s", ref, current, old)
+	}
+	if ref != "HEAD" {
+		if f.Refs == nil {
+			f.Refs = map[string]string{}
+		}
+		f.Refs[ref] = new
+		return nil
+	}
+	rewritten := []Commit{}
+	sha := new
+	for !slices.ContainsFunc(f.History, func(c Commit) bool { return c.SHA == sha }) {
+		commit, ok := f.lookup(sha)
+		if !ok {
+			break
+		}
+		rewritten = append(rewritten, commit)
+		sha = first(commit.Parents)
+	}
+	rest := slices.IndexFunc(f.History, func(c Commit) bool { return c.SHA == sha })
+	if rest < 0 {
+		rest = len(f.History)
+	}
+	f.History = append(rewritten, f.History[rest:]...)
+	return nil
+}
+
+// match a glob the way `git tag --list` does, where `*` matches slashes
+func globMatch(glob string, name string) bool {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	return regexp.MustCompile("^" + pattern + "$").MatchString(name)
+}
+
+// the tags pointing at a commit that match a glob, sorted
+func (f *Fake) tagsAt(sha string, match string) []string {
+	tags := []string{}
+	for ref, target := range f.Refs {
+		if tag, ok := strings.CutPrefix(ref, "refs/tags/"); ok && target == sha && globMatch(match, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+func (f *Fake) Tags(ref string, match string) ([]string, error) {
+	sha, err := f.ResolveRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	tags := []string{}
+	for _, reachable := range f.reachable(sha) {
+		tags = append(tags, f.tagsAt(reachable, match)...)
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
+
+func (f *Fake) NearestTag(ref string, match string, exclude string) (string, error) {
+	sha, err := f.ResolveRef(ref)
+	if err != nil {
+		return "", err
+	}
+	for _, reachable := range f.reachable(sha) {
+		for _, tag := range f.tagsAt(reachable, match) {
+			if exclude == "" || !globMatch(exclude, tag) {
+				return tag, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no tags match %s", match)
+}
+
+func (f *Fake) TagAt(ref string, match string) (string, error) {
+	sha, err := f.ResolveRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if tags := f.tagsAt(sha, match); len(tags) > 0 {
+		return tags[0], nil
+	}
+	return "", fmt.Errorf("no tag exactly matches %s", sha)
+}
+
+func (f *Fake) CreateTag(name string, ref string, message string) error {
+	sha, err := f.ResolveRef(ref)
+	if err != nil {
+		return err
+	}
+	if _, exists := f.Refs["refs/tags/"+name]; exists {
+		return fmt.Errorf("tag '%s' already exists", name)
+	}
+	if f.Refs == nil {
+		f.Refs = map[string]string{}
+	}
+	f.Refs["refs/tags/"+name] = sha
+	return nil
+}
+
+func (f *Fake) RemoteURL(name string) (string, error) {
+	if url, ok := f.Remotes[name]; ok {
+		return url, f.Err
+	}
+	return "", fmt.Errorf("no such remote '%s'", name)
+}
+
+func (f *Fake) GitPath(name string) (string, error) {
+	return filepath.Join(f.GitDirPath, name), f.Err
+}
+
+func (f *Fake) Var(name string) (string, error) {
+	if value, ok := f.Vars[name]; ok {
+		return value, f.Err
+	}
+	return "", fmt.Errorf("unknown variable %s", name)
+}
+
+// a Command that does nothing
+type fakeCommand struct{}
+
+func (fakeCommand) Run() error          { return nil }
+func (fakeCommand) SetStdin(io.Reader)  {}
+func (fakeCommand) SetStdout(io.Writer) {}
+func (fakeCommand) SetStderr(io.Writer) {}
+
+// record the paths, rather than paging through anything
+func (f *Fake) PageStagedDiff(paths ...string) Command {
+	f.Paged = append(f.Paged, paths)
+	return fakeCommand{}
+}
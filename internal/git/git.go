@@ -0,0 +1,145 @@
+// Package git is how git-cc reads and writes the repository it runs in. Repo
+// is implemented by running git (ExecRepo) and by a scripted fake for tests
+// (Fake).
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+	"os/exec"
+	"strings"
+)
+
+// a commit as `git log` reports it
+type Commit struct {
+	SHA     string
+	Parents []string
+	Author  string
+	Email   string
+	// the author date, in strict ISO 8601 format
+	Date    string
+	Message string
+}
+
+// the first line of the commit's message
+func (c Commit) Header() string {
+	header, _, _ := strings.Cut(c.Message, "\n")
+	return header
+}
+
+// how a staged change adds to and deletes from a file, as `git diff --stat`
+// counts it
+type FileStat struct {
+	Path string
+	// the file's former path if it was renamed, else ""
+	From    string
+	Added   int
+	Deleted int
+	// binary files have no line counts
+	Binary bool
+}
+
+type Repo interface {
+	// the absolute path of the git directory, usually the work tree's .git
+	GitDir() (string, error)
+	// the root of the work tree
+	Root() (string, error)
+	// the name of the checked-out branch, or "HEAD" if it's detached
+	Branch() (string, error)
+	// the paths of the changes staged for the next commit, relative to Root
+	StagedFiles() ([]string, error)
+	// the changes staged for the next commit, file by file
+	StagedStats() ([]FileStat, error)
+	// the message of the commit HEAD points to
+	HeadMessage() (string, error)
+	// the commits `git log` lists given arguments like a revision range,
+	// stopping early if the loop does
+	Log(args ...string) iter.Seq2[Commit, error]
+	// the value of a git config key like core.commentChar, if it's set
+	ConfigGet(key string) (string, bool)
+	// the git config keys starting with a prefix like "cc.", lower-cased, and
+	// their values
+	ConfigPrefixed(prefix string) map[string]string
+	// run `git commit` on the terminal with a message and other arguments
+	Commit(message string, args ...string) error
+
+	// the full hash of the commit a revision like `HEAD~2` or `v1.2.0` names
+	ResolveRef(rev string) (string, error)
+	// whether `commit` is reachable from `ref`
+	IsAncestor(commit string, ref string) bool
+	// the short names of the branches, remote-tracking branches, and tags
+	// matching globs like `main` or `release/*`
+	ListRefs(patterns ...string) ([]string, error)
+	// the paths of the files a commit changes, relative to Root
+	ChangedFiles(commit string) ([]string, error)
+	// a commit object as `git cat-file commit` prints it
+	CatCommit(commit string) (string, error)
+	// store a commit object, returning its hash
+	WriteCommit(raw string) (string, error)
+	// point a ref like HEAD at `new`, as long as it still points at `old`
+	UpdateRef(ref string, new string, old string, reason string) error
+
+	// the tags matching a glob that are reachable from a ref
+	Tags(ref string, match string) ([]string, error)
+	// the nearest tag matching a glob that's reachable from a ref, other than
+	// any matching `exclude`
+	NearestTag(ref string, match string, exclude string) (string, error)
+	// a tag matching a glob that points at a ref
+	TagAt(ref string, match string) (string, error)
+	// create an annotated tag of a ref
+	CreateTag(name string, ref string, message string) error
+
+	// the URL of a remote like `origin`
+	RemoteURL(name string) (string, error)
+	// the absolute path of a file git keeps in the git directory, like
+	// `hooks`, accounting for core.hooksPath and worktrees
+	GitPath(name string) (string, error)
+	// a git logical variable like GIT_EDITOR
+	Var(name string) (string, error)
+	// page through the changes staged to some paths, relative to Root
+	PageStagedDiff(paths ...string) Command
+}
+
+// a process that takes over the terminal, like a pager. It fits bubbletea's
+// tea.ExecCommand.
+type Command interface {
+	Run() error
+	SetStdin(io.Reader)
+	SetStdout(io.Writer)
+	SetStderr(io.Writer)
+}
+
+// run git with the given stdin, returning its stdout. Errors include what git
+// printed to stderr.
+func Output(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	process := exec.Command("git", args...)
+	process.Stdin = strings.NewReader(stdin)
+	process.Stdout = &stdout
+	process.Stderr = &stderr
+	if err := process.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// git config keys are case-insensitive except for any subsection, like the
+// `Origin` in `remote.Origin.url`
+func normalizeKey(key string) string {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first < 0 {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
+
+// the first of a log's commits' message
+func headMessage(log iter.Seq2[Commit, error]) (string, error) {
+	for commit, err := range log {
+		return commit.Message, err
+	}
+	return "", fmt.Errorf("HEAD has no commits")
+}
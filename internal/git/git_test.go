@@ -0,0 +1,231 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	test := func(key string, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			if actual := normalizeKey(key); actual != expected {
+				fmt.Printf("expected %q, got %q\n", expected, actual)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("section and name", test("core.commentChar", "core.commentchar"))
+	t.Run("subsection", test("Remote.Origin.URL", "remote.Origin.url"))
+	t.Run("no section", test("Bare", "bare"))
+}
+
+func TestParseConfigList(t *testing.T) {
+	config := parseConfigList("cc.preset\nangular\x00Core.CommentChar\n;\x00core.bare\x00cc.preset\nconventional\x00")
+	expected := map[string]string{
+		"cc.preset":        "conventional",
+		"core.commentchar": ";",
+		"core.bare":        "true",
+	}
+	for key, value := range expected {
+		if config[key] != value {
+			fmt.Printf("expected %s = %q, got %q\n", key, value, config[key])
+			t.Fail()
+		}
+	}
+	if len(config) != len(expected) {
+		fmt.Printf("unexpected keys: %v\n", config)
+		t.Fail()
+	}
+}
+
+func TestParseLogRecord(t *testing.T) {
+	commit, err := parseLogRecord("\nabc\x1fdef 012\x1fA U Thor\x1fau@thor.example\x1f2024-01-02T03:04:05+00:00\x1ffeat: add x\n\nbody\n\n")
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	expected := Commit{
+		SHA:     "abc",
+		Parents: []string{"def", "012"},
+		Author:  "A U Thor",
+		Email:   "au@thor.example",
+		Date:    "2024-01-02T03:04:05+00:00",
+		Message: "feat: add x\n\nbody",
+	}
+	if commit.SHA != expected.SHA || !slices.Equal(commit.Parents, expected.Parents) ||
+		commit.Author != expected.Author || commit.Email != expected.Email ||
+		commit.Date != expected.Date || commit.Message != expected.Message {
+		fmt.Printf("expected %+v\ngot      %+v\n", expected, commit)
+		t.Fail()
+	}
+	if commit.Header() != "feat: add x" {
+		fmt.Printf("unexpected header %q\n", commit.Header())
+		t.Fail()
+	}
+	if _, err := parseLogRecord("abc\x1fdef"); err == nil {
+		fmt.Println("expected an error for a truncated record")
+		t.Fail()
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	stats, err := parseNumstat("3\t1\tREADME.md\x00-\t-\tlogo.png\x000\t0\t\x00old.go\x00new.go\x00")
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	expected := []FileStat{
+		{Path: "README.md", Added: 3, Deleted: 1},
+		{Path: "logo.png", Binary: true},
+		{Path: "new.go", From: "old.go"},
+	}
+	if !slices.Equal(stats, expected) {
+		fmt.Printf("expected %+v\ngot      %+v\n", expected, stats)
+		t.Fail()
+	}
+}
+
+func TestFake(t *testing.T) {
+	history := []Commit{{SHA: "c", Message: "third"}, {SHA: "b", Message: "second"}, {SHA: "a", Message: "first"}}
+	log := func(fake *Fake, args ...string) (shas []string) {
+		for commit, err := range fake.Log(args...) {
+			if err != nil {
+				fmt.Println(err)
+				t.FailNow()
+			}
+			shas = append(shas, commit.SHA)
+		}
+		return shas
+	}
+	t.Run("log", func(t *testing.T) {
+		fake := &Fake{
+			History: history,
+			Refs:    map[string]string{"refs/tags/v1.0.0": "a", "refs/heads/main": "b"},
+			Changes: map[string][]string{"a": {"README.md"}, "b": {"pkg/parser/parser.go"}, "c": {"pkg/semver/semver.go"}},
+		}
+		for _, c := range []struct {
+			args     []string
+			expected []string
+		}{
+			{nil, []string{"c", "b", "a"}},
+			{[]string{"-1", "HEAD", "--"}, []string{"c"}},
+			{[]string{"-n", "2"}, []string{"c", "b"}},
+			{[]string{"--reverse", "a..c"}, []string{"b", "c"}},
+			{[]string{"-2", "--reverse"}, []string{"b", "c"}},
+			{[]string{"v1.0.0..HEAD"}, []string{"c", "b"}},
+			{[]string{"main"}, []string{"b", "a"}},
+			{[]string{"HEAD~1", "^a"}, []string{"b"}},
+			{[]string{"..HEAD"}, nil},
+			{[]string{"HEAD", "--", ":(top)pkg/parser"}, []string{"b"}},
+			{[]string{"HEAD", "--", "pkg"}, []string{"c", "b"}},
+		} {
+			if actual := log(fake, c.args...); !slices.Equal(actual, c.expected) {
+				fmt.Printf("%q: expected %v, got %v\n", c.args, c.expected, actual)
+				t.Fail()
+			}
+		}
+	})
+	t.Run("commit", func(t *testing.T) {
+		fake := &Fake{History: slices.Clone(history), Staged: []string{"x"}}
+		if err := fake.Commit("fourth", "--no-edit"); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if message, _ := fake.HeadMessage(); message != "fourth" {
+			fmt.Printf("expected HEAD to be the new commit, got %q\n", message)
+			t.Fail()
+		}
+		if err := fake.Commit("nothing staged"); err == nil {
+			fmt.Println("expected committing without staged changes to fail")
+			t.Fail()
+		}
+		if err := fake.Commit("fourth, amended", "--amend"); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if shas := log(fake); len(shas) != 4 || fake.History[0].Parents[0] != "c" {
+			fmt.Printf("expected --amend to replace HEAD, got %v\n", fake.History)
+			t.Fail()
+		}
+		if len(fake.Committed) != 2 || !slices.Equal(fake.Committed[0].Args, []string{"--no-edit"}) {
+			fmt.Printf("unexpected commits %+v\n", fake.Committed)
+			t.Fail()
+		}
+	})
+	t.Run("rewrite", func(t *testing.T) {
+		fake := &Fake{History: []Commit{
+			{SHA: "c", Message: "third", Author: "A U Thor", Email: "au@thor.example", Date: "2024-01-02T03:04:05+01:00"},
+			{SHA: "b", Message: "second"},
+			{SHA: "a", Message: "first", Parents: []string{}},
+		}}
+		raw, err := fake.CatCommit("HEAD")
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if expected := "parent b\nauthor A U Thor <au@thor.example> 1704161045 +0100\n"; !strings.Contains(raw, expected) {
+			fmt.Printf("expected %q in\n%s\n", expected, raw)
+			t.Fail()
+		}
+		sha, err := fake.WriteCommit(strings.Replace(raw, "third", "3rd", 1))
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if err := fake.UpdateRef("HEAD", sha, "b", "test"); err == nil {
+			fmt.Println("expected moving HEAD from the wrong commit to fail")
+			t.Fail()
+		}
+		if err := fake.UpdateRef("HEAD", sha, "c", "test"); err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if shas := log(fake); !slices.Equal(shas, []string{sha, "b", "a"}) {
+			fmt.Printf("expected HEAD to be rewritten, got %v\n", shas)
+			t.Fail()
+		}
+		if commit := fake.History[0]; commit.Message != "3rd" || commit.Date != "2024-01-02T03:04:05+01:00" {
+			fmt.Printf("expected the message to change and the date to stay the same, got %+v\n", commit)
+			t.Fail()
+		}
+	})
+	t.Run("tags", func(t *testing.T) {
+		fake := &Fake{History: history, Refs: map[string]string{
+			"refs/tags/v1.0.0":            "a",
+			"refs/tags/pkg/parser/v0.1.0": "b",
+			"refs/tags/v1.1.0":            "b",
+		}}
+		if tags, _ := fake.Tags("HEAD~1", "v*"); !slices.Equal(tags, []string{"v1.0.0", "v1.1.0"}) {
+			fmt.Printf("unexpected tags %v\n", tags)
+			t.Fail()
+		}
+		if tag, _ := fake.NearestTag("HEAD", "v*", "v1.1.0"); tag != "v1.0.0" {
+			fmt.Printf("expected the nearest tag other than v1.1.0 to be v1.0.0, got %q\n", tag)
+			t.Fail()
+		}
+		if tag, _ := fake.TagAt("b", "pkg/parser/v*"); tag != "pkg/parser/v0.1.0" {
+			fmt.Printf("unexpected tag %q\n", tag)
+			t.Fail()
+		}
+		if _, err := fake.TagAt("HEAD", "*"); err == nil {
+			fmt.Println("expected HEAD to be untagged")
+			t.Fail()
+		}
+	})
+}
+
+func TestPageStagedDiff(t *testing.T) {
+	t.Setenv("LESS", "")
+	command := (&ExecRepo{}).PageStagedDiff("new.go", "old.go").(execCommand)
+	// paths are relative to the root, wherever git-cc runs
+	if expected := []string{"git", "--paginate", "diff", "--cached", "-M", "--", ":(top)new.go", ":(top)old.go"}; !slices.Equal(command.Args, expected) {
+		fmt.Printf("expected %q, got %q\n", expected, command.Args)
+		t.Fail()
+	}
+	if !slices.Contains(command.Env, "LESS=RX") {
+		fmt.Println("expected less not to quit short diffs at once")
+		t.Fail()
+	}
+}
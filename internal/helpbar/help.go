@@ -47,10 +47,11 @@ func (m Model) Render(s io.StringWriter) {
 		if currentLen+sepLen+ansi.PrintableRuneWidth(item) <= m.width {
 			_ = must(s.WriteString(sep))
 			_ = must(s.WriteString(config.Faint(item)))
-			currentLen += sepLen + len(item)
+			currentLen += sepLen + ansi.PrintableRuneWidth(item)
 		} else {
 			_ = must(s.WriteString("\n"))
-			currentLen = must(s.WriteString(config.Faint(item)))
+			_ = must(s.WriteString(config.Faint(item)))
+			currentLen = ansi.PrintableRuneWidth(item)
 		}
 	}
 }
@@ -0,0 +1,108 @@
+package i18n
+
+// translations of the TUI's prompts and help, and of the descriptions of the
+// angular-style commit types. Catalogs live in ./locales, one per locale.
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultLocale = "en"
+
+//go:embed locales/*.yaml
+var catalogFiles embed.FS
+
+var (
+	catalogs = loadCatalogs()
+	current  = DefaultLocale
+)
+
+func loadCatalogs() map[string]map[string]string {
+	result := map[string]map[string]string{}
+	entries, err := catalogFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := catalogFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		catalog := map[string]string{}
+		if err := yaml.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Errorf("%s: %w", entry.Name(), err))
+		}
+		result[strings.TrimSuffix(entry.Name(), ".yaml")] = catalog
+	}
+	return result
+}
+
+// find the catalog for a locale like `pt_BR.UTF-8`, `pt-BR`, or `ja`,
+// falling back to a catalog for the same language. Returns "" if there is none.
+func resolve(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".") // drop any encoding, e.g. `.UTF-8`
+	locale, _, _ = strings.Cut(locale, "@") // and any modifier, e.g. `@euro`
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	for available := range catalogs {
+		if strings.EqualFold(available, locale) {
+			return available
+		}
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	for available := range catalogs {
+		availableLanguage, _, _ := strings.Cut(available, "-")
+		if strings.EqualFold(availableLanguage, language) {
+			return available
+		}
+	}
+	return ""
+}
+
+// pick a locale: the configured one, if any, else the first of $LC_ALL,
+// $LC_MESSAGES, and $LANG with a catalog.
+func Detect(configured string) string {
+	candidates := []string{configured}
+	for _, env := range [...]string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		candidates = append(candidates, os.Getenv(env))
+	}
+	for _, candidate := range candidates {
+		if locale := resolve(candidate); locale != "" {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// use the catalog of the given locale, if any
+func SetLocale(locale string) {
+	if resolved := resolve(locale); resolved != "" {
+		current = resolved
+	} else {
+		current = DefaultLocale
+	}
+}
+
+func Locale() string {
+	return current
+}
+
+// look up a message in the current locale's catalog, falling back to English,
+// then to the key itself.
+func T(key string) string {
+	if message, ok := catalogs[current][key]; ok {
+		return message
+	}
+	if message, ok := catalogs[DefaultLocale][key]; ok {
+		return message
+	}
+	return key
+}
@@ -0,0 +1,41 @@
+package i18n
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	test := func(locale string, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			if actual := resolve(locale); actual != expected {
+				fmt.Printf("expected %q, got %q\n", expected, actual)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("exact", test("ja", "ja"))
+	t.Run("posix locale", test("pt_BR.UTF-8", "pt-BR"))
+	t.Run("language only", test("pt", "pt-BR"))
+	t.Run("case-insensitive", test("PT-br", "pt-BR"))
+	t.Run("modifier", test("en_US@euro", "en"))
+	t.Run("C", test("C.UTF-8", ""))
+	t.Run("unknown", test("de_DE", ""))
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for key := range catalogs[DefaultLocale] {
+			if _, ok := catalog[key]; !ok {
+				fmt.Printf("%s is missing %q\n", locale, key)
+				t.Fail()
+			}
+		}
+		for key := range catalog {
+			if _, ok := catalogs[DefaultLocale][key]; !ok {
+				fmt.Printf("%s has unknown key %q\n", locale, key)
+				t.Fail()
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package lint
+
+// a commitlint-style rules engine; see https://commitlint.js.org/reference/rules.html
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/pkg/parser"
+)
+
+type Violation struct {
+	Rule    string
+	Level   config.RuleLevel
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s [%s]", v.Level, v.Message, v.Rule)
+}
+
+// The part of the commit a rule checks, e.g. "type", "scope", "subject",
+// "header", "body", or "footer".
+func (v Violation) Target() string {
+	return strings.SplitN(v.Rule, "-", 2)[0]
+}
+
+// the parts of a commit message that rules inspect.
+type commit struct {
+	cc     *parser.CC
+	header string
+	lines  []string
+}
+
+// checks whether a commit passes a rule. `never` is true when the rule is applied
+// with "never", inverting its condition. `problem` describes the violation, e.g.
+// "subject must not be empty".
+type check func(c *commit, value interface{}, never bool) (pass bool, problem string)
+
+// "must" or "must not"
+func modal(never bool) string {
+	if never {
+		return "must not"
+	}
+	return "must"
+}
+
+var checks = map[string]check{
+	"type-empty":           emptyCheck("type", func(c *commit) string { return c.cc.Type }),
+	"type-enum":            typeEnum,
+	"type-case":            caseCheck("type", func(c *commit) string { return c.cc.Type }),
+	"scope-empty":          emptyCheck("scope", func(c *commit) string { return c.cc.Scope }),
+	"scope-enum":           scopeEnum,
+	"scope-case":           caseCheck("scope", func(c *commit) string { return c.cc.Scope }),
+	"scope-deprecated":     scopeDeprecated,
+	"subject-empty":        emptyCheck("subject", func(c *commit) string { return c.cc.Description }),
+	"subject-case":         caseCheck("subject", func(c *commit) string { return c.cc.Description }),
+	"subject-full-stop":    subjectFullStop,
+	"header-max-length":    headerMaxLength,
+	"header-min-length":    headerMinLength,
+	"body-leading-blank":   bodyLeadingBlank,
+	"body-max-line-length": bodyMaxLineLength,
+	"footer-leading-blank": footerLeadingBlank,
+	"footer-required":      footerRequired,
+}
+
+// the rules that are enforced if a config file doesn't say otherwise. These
+// mirror git-cc's validation before rules were configurable.
+func DefaultRules(cfg *config.Cfg) *config.Rules {
+	rules := config.NewRules()
+	rules.Set("type-empty", config.RuleConfig{Level: config.RuleError, Applicable: "never"})
+	rules.Set("type-enum", config.RuleConfig{Level: config.RuleError, Applicable: "always"})
+	rules.Set("scope-enum", config.RuleConfig{Level: config.RuleError, Applicable: "always"})
+	rules.Set("scope-deprecated", config.RuleConfig{Level: config.RuleWarn, Applicable: "never"})
+	rules.Set("subject-empty", config.RuleConfig{Level: config.RuleError, Applicable: "never"})
+	rules.Set("footer-required", config.RuleConfig{Level: config.RuleError, Applicable: "always"})
+	headerMaxLength := config.RuleConfig{
+		Level: config.RuleWarn, Applicable: "always", Value: cfg.HeaderMaxLength,
+	}
+	if cfg.EnforceMaxLength {
+		headerMaxLength.Level = config.RuleError
+	}
+	rules.Set("header-max-length", headerMaxLength)
+	return rules
+}
+
+// the default rules, overridden by any configured rules. A rule configured
+// as just a level keeps the default's applicability and value.
+func EffectiveRules(cfg *config.Cfg) *config.Rules {
+	return config.MergeRules(DefaultRules(cfg), cfg.Rules)
+}
+
+// rules that only make sense applied "always"
+var alwaysOnly = map[string]bool{
+	"header-max-length":    true,
+	"header-min-length":    true,
+	"body-max-line-length": true,
+}
+
+// check that every configured rule names a known rule and is applied in a
+// way it supports
+func Validate(rules *config.Rules) error {
+	for pair := rules.Oldest(); pair != nil; pair = pair.Next() {
+		if _, known := checks[pair.Key]; !known {
+			return fmt.Errorf("unknown rule: %s", pair.Key)
+		}
+		if alwaysOnly[pair.Key] && pair.Value.Applicable == "never" {
+			return fmt.Errorf("rule %s can't be applied with \"never\"", pair.Key)
+		}
+	}
+	return nil
+}
+
+// Run every enabled rule against a commit message, returning every violation.
+// `message` should be the full message `cc` was parsed from.
+func Lint(cfg *config.Cfg, cc *parser.CC, message string) (violations []Violation) {
+	c := &commit{cc: cc, lines: strings.Split(strings.TrimRight(message, "\r\n\t "), "\n")}
+	c.header = strings.TrimRight(c.lines[0], "\r")
+	rules := EffectiveRules(cfg)
+	for pair := rules.Oldest(); pair != nil; pair = pair.Next() {
+		name, rule := pair.Key, pair.Value
+		if rule.Level == config.RuleOff {
+			continue
+		}
+		check, known := checks[name]
+		if !known {
+			continue // reported by Validate
+		}
+		value := rule.Value
+		switch name { // some rules' values come from the rest of the config
+		case "type-enum":
+			if value == nil {
+				value = cfg.CommitTypes
+			}
+		case "scope-enum":
+			if value == nil {
+				value = cfg.Scopes
+			}
+		case "scope-deprecated":
+			if value == nil {
+				value = cfg.DeprecatedScopes()
+			}
+		case "footer-required": // required_footers says which, not the rule
+			value = cfg.MissingFooters(cc.Type, cc.Footers)
+		}
+		if pass, problem := check(c, value, rule.Applicable == "never"); !pass {
+			violations = append(violations, Violation{
+				Rule:    name,
+				Level:   rule.Level,
+				Message: problem,
+			})
+		}
+	}
+	return violations
+}
+
+// whether any of the violations are errors rather than warnings
+func HasErrors(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Level >= config.RuleError {
+			return true
+		}
+	}
+	return false
+}
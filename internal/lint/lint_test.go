@@ -0,0 +1,188 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/pkg/parser"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func testCfg(rules map[string]config.RuleConfig) *config.Cfg {
+	types := orderedmap.New[string, string]()
+	types.Set("feat", "adds a new feature")
+	types.Set("fix", "fixes a bug")
+	scopes := orderedmap.New[string, string]()
+	scopes.Set("parser", "parses conventional commits")
+	cfg := &config.Cfg{
+		CommitTypes:     types,
+		Scopes:          scopes,
+		HeaderMaxLength: 72,
+		Rules:           config.NewRules(),
+	}
+	for name, rule := range rules {
+		cfg.Rules.Set(name, rule)
+	}
+	return cfg
+}
+
+func TestLint(t *testing.T) {
+	test := func(cfg *config.Cfg, message string, expected ...string) func(*testing.T) {
+		return func(t *testing.T) {
+			cc, _ := parser.ParseAsMuchOfCCAsPossible(message)
+			violations := Lint(cfg, cc, message)
+			if len(violations) != len(expected) {
+				fmt.Printf("expected %v, got %+v\n", expected, violations)
+				t.FailNow()
+			}
+			for i, v := range violations {
+				if v.Rule != expected[i] {
+					fmt.Printf("expected %s, got %s\n", expected[i], v.Rule)
+					t.Fail()
+				}
+			}
+		}
+	}
+	defaults := testCfg(nil)
+	t.Run("valid commits pass", test(defaults, "feat(parser): add a rule"))
+	t.Run("unknown types and scopes are reported", test(
+		defaults, "chore(cli): add a rule", "type-enum", "scope-enum",
+	))
+	t.Run("every default violation is reported", test(
+		defaults, "", "type-empty", "subject-empty",
+	))
+	t.Run("long headers are reported", test(
+		defaults, "fix: "+strings.Repeat("a", 80), "header-max-length",
+	))
+	never := func(level config.RuleLevel, value interface{}) config.RuleConfig {
+		return config.RuleConfig{Level: level, Applicable: "never", Value: value}
+	}
+	always := func(level config.RuleLevel, value interface{}) config.RuleConfig {
+		return config.RuleConfig{Level: level, Applicable: "always", Value: value}
+	}
+	t.Run("subject-full-stop", test(
+		testCfg(map[string]config.RuleConfig{"subject-full-stop": never(config.RuleError, ".")}),
+		"fix: a typo.",
+		"subject-full-stop",
+	))
+	t.Run("subject-case", test(
+		testCfg(map[string]config.RuleConfig{
+			"subject-case": never(config.RuleWarn, []interface{}{"sentence-case", "upper-case"}),
+		}),
+		"fix: Fix a typo",
+		"subject-case",
+	))
+	t.Run("disabled rules are skipped", test(
+		testCfg(map[string]config.RuleConfig{"type-enum": always(config.RuleOff, nil)}),
+		"chore: bump deps",
+	))
+	t.Run("body-leading-blank", test(
+		testCfg(map[string]config.RuleConfig{"body-leading-blank": always(config.RuleWarn, nil)}),
+		"fix: a typo\nin the body",
+		"body-leading-blank",
+	))
+	t.Run("footer-leading-blank", test(
+		testCfg(map[string]config.RuleConfig{"footer-leading-blank": always(config.RuleWarn, nil)}),
+		"fix: a typo\n\nbody\nRefs: #1",
+		"footer-leading-blank",
+	))
+	t.Run("header-min-length", test(
+		testCfg(map[string]config.RuleConfig{"header-min-length": always(config.RuleError, 10)}),
+		"fix: typo",
+		"header-min-length",
+	))
+	deprecated := testCfg(nil)
+	deprecated.Scopes.Set("cli", "the old name of the parser")
+	deprecated.ScopeInfo = map[string]config.ScopeInfo{"cli": {Deprecated: "use parser instead"}}
+	t.Run("deprecated scopes are warned about", test(
+		deprecated, "fix(cli): typo", "scope-deprecated",
+	))
+	t.Run("scope-case", test(
+		testCfg(map[string]config.RuleConfig{
+			"scope-case": always(config.RuleError, "kebab-case"),
+			"scope-enum": always(config.RuleOff, nil),
+		}),
+		"fix(Parser): typo",
+		"scope-case",
+	))
+	levelOnly := testCfg(map[string]config.RuleConfig{
+		"subject-empty":     {Level: config.RuleError},
+		"type-empty":        {Level: config.RuleError},
+		"header-max-length": {Level: config.RuleError},
+	})
+	t.Run("rules given as just a level keep the defaults' conditions", test(levelOnly, "feat: x"))
+	t.Run("rules given as just a level still apply", test(
+		levelOnly, "fix: "+strings.Repeat("a", 80), "header-max-length",
+	))
+	t.Run("rules given as just a level apply always", test(
+		testCfg(map[string]config.RuleConfig{"body-leading-blank": {Level: config.RuleWarn}}),
+		"fix: a typo\nin the body",
+		"body-leading-blank",
+	))
+}
+
+func TestScopeDeprecatedLevel(t *testing.T) {
+	cfg := testCfg(map[string]config.RuleConfig{"scope-deprecated": {Level: config.RuleError}})
+	cfg.Scopes.Set("old", "the old parser")
+	cfg.ScopeInfo = map[string]config.ScopeInfo{"old": {Deprecated: "use parser instead"}}
+	message := "feat(old): x"
+	cc, _ := parser.ParseAsMuchOfCCAsPossible(message)
+	violations := Lint(cfg, cc, message)
+	if len(violations) != 1 || violations[0].Rule != "scope-deprecated" || violations[0].Level != config.RuleError {
+		fmt.Printf("expected a scope-deprecated error, got %+v\n", violations)
+		t.Fail()
+	}
+	if !HasErrors(violations) {
+		fmt.Println("expected the deprecated scope to be rejected")
+		t.Fail()
+	}
+}
+
+func TestFooterRequired(t *testing.T) {
+	for name, rule := range map[string]config.RuleConfig{
+		"by default":            {},
+		"given as just a level": {Level: config.RuleWarn},
+		"given a value":         {Level: config.RuleError, Applicable: "always", Value: []interface{}{"Refs"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := testCfg(nil)
+			if rule.Level != config.RuleOff {
+				cfg.Rules.Set("footer-required", rule)
+			}
+			cfg.RequiredFooters = map[string][]config.RequiredFooter{"fix": {{Token: "Refs"}}}
+			for message, missing := range map[string]bool{"fix: x": true, "fix: x\n\nRefs: #1": false, "feat: x": false} {
+				cc, _ := parser.ParseAsMuchOfCCAsPossible(message)
+				violations := Lint(cfg, cc, message)
+				if reported := len(violations) == 1 && violations[0].Rule == "footer-required"; reported != missing || (!missing && len(violations) > 0) {
+					fmt.Printf("%q: expected missing footers to be reported (%v), got %+v\n", message, missing, violations)
+					t.Fail()
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		rule  config.RuleConfig
+		valid bool
+	}{
+		{"subject-empty", config.RuleConfig{Level: config.RuleError, Applicable: "never"}, true},
+		{"header-min-length", config.RuleConfig{Level: config.RuleError, Applicable: "always", Value: 10}, true},
+		{"header-min-length", config.RuleConfig{Level: config.RuleError}, true},
+		{"header-min-length", config.RuleConfig{Level: config.RuleError, Applicable: "never", Value: 10}, false},
+		{"header-max-length", config.RuleConfig{Level: config.RuleError, Applicable: "never", Value: 10}, false},
+		{"body-max-line-length", config.RuleConfig{Level: config.RuleWarn, Applicable: "never", Value: 80}, false},
+		{"subject-length", config.RuleConfig{Level: config.RuleError}, false},
+	} {
+		rules := config.NewRules()
+		rules.Set(c.name, c.rule)
+		if err := Validate(rules); (err == nil) != c.valid {
+			fmt.Printf("%s %+v: expected valid to be %v, got %v\n", c.name, c.rule, c.valid, err)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package lint
+
+// reports of linting many commits, for people and for CI systems
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/skalt/git-cc/internal/config"
+)
+
+var ReportFormats = [...]string{"text", "json", "junit", "sarif", "github"}
+
+// the outcome of linting one commit message
+type Result struct {
+	// the commit's full hash, or "" for a message that isn't committed yet
+	Commit string
+	Header string
+	// why the message wasn't checked, e.g. "merge commit"; "" if it was
+	Skipped    string
+	Violations []Violation
+}
+
+// an abbreviated commit hash, like `git log --oneline` shows
+func (r Result) ShortCommit() string {
+	if len(r.Commit) > 7 {
+		return r.Commit[:7]
+	}
+	return r.Commit
+}
+
+// how a result is named in reports, e.g. "abc1234 feat: add refunds"
+func (r Result) name() string {
+	if r.Commit == "" {
+		return r.Header
+	}
+	return r.ShortCommit() + " " + r.Header
+}
+
+func WriteReport(w io.Writer, format string, results []Result) error {
+	switch format {
+	case "text":
+		return writeText(w, results)
+	case "json":
+		return writeJson(w, results)
+	case "junit":
+		return writeJunit(w, results)
+	case "sarif":
+		return writeSarif(w, results)
+	case "github":
+		return writeGithub(w, results)
+	default:
+		return fmt.Errorf("unknown report format %q; expected one of %v", format, ReportFormats)
+	}
+}
+
+// one line per checked commit followed by its violations. Violations of an
+// uncommitted message aren't indented.
+func writeText(w io.Writer, results []Result) (err error) {
+	for _, r := range results {
+		indent := ""
+		if r.Commit != "" {
+			indent = "  "
+			status := "ok"
+			if r.Skipped != "" {
+				status = "skipped: " + r.Skipped
+			} else if len(r.Violations) > 0 {
+				status = fmt.Sprintf("%d problem(s)", len(r.Violations))
+			}
+			if _, err = fmt.Fprintf(w, "%s (%s)\n", r.name(), status); err != nil {
+				return err
+			}
+		}
+		for _, v := range r.Violations {
+			if _, err = fmt.Fprintf(w, "%s%s\n", indent, v.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type jsonViolation struct {
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type jsonResult struct {
+	Commit     string          `json:"commit,omitempty"`
+	Header     string          `json:"header"`
+	Skipped    string          `json:"skipped,omitempty"`
+	Violations []jsonViolation `json:"violations"`
+}
+
+func writeJson(w io.Writer, results []Result) error {
+	out := make([]jsonResult, 0, len(results))
+	for _, r := range results {
+		violations := make([]jsonViolation, 0, len(r.Violations))
+		for _, v := range r.Violations {
+			violations = append(violations, jsonViolation{v.Rule, v.Level.String(), v.Message})
+		}
+		out = append(out, jsonResult{r.Commit, r.Header, r.Skipped, violations})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// see https://github.com/testmoapp/junitxml
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	// warnings, which don't fail the test case
+	SystemOut string `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// one test case per commit, failing if it has any error-level violations
+func writeJunit(w io.Writer, results []Result) error {
+	suite := junitTestSuite{Name: "git-cc lint", Tests: len(results)}
+	for _, r := range results {
+		testCase := junitTestCase{Name: r.name(), Classname: "commits"}
+		if r.Skipped != "" {
+			testCase.Skipped = &junitSkipped{r.Skipped}
+			suite.Skipped++
+		}
+		errors, warnings := []string{}, []string{}
+		for _, v := range r.Violations {
+			if v.Level >= config.RuleError {
+				errors = append(errors, v.String())
+			} else {
+				warnings = append(warnings, v.String())
+			}
+		}
+		if len(errors) > 0 {
+			testCase.Failure = &junitFailure{errors[0], strings.Join(errors, "\n")}
+			suite.Failures++
+		}
+		testCase.SystemOut = strings.Join(warnings, "\n")
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// commits have no file to point to, so they're logical locations
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+func writeSarif(w io.Writer, results []Result) error {
+	driver := sarifDriver{Name: "git-cc", InformationUri: "https://github.com/skalt/git-cc", Rules: []sarifRule{}}
+	seen := map[string]bool{}
+	out := []sarifResult{}
+	for _, r := range results {
+		for _, v := range r.Violations {
+			if !seen[v.Rule] {
+				seen[v.Rule] = true
+				driver.Rules = append(driver.Rules, sarifRule{v.Rule})
+			}
+			level := "warning"
+			if v.Level >= config.RuleError {
+				level = "error"
+			}
+			location := sarifLogicalLocation{Name: r.Header, FullyQualifiedName: r.Commit, Kind: "commit"}
+			out = append(out, sarifResult{
+				RuleId:    v.Rule,
+				Level:     level,
+				Message:   sarifMessage{fmt.Sprintf("%s: %s", r.name(), v.Message)},
+				Locations: []sarifLocation{{[]sarifLogicalLocation{location}}},
+			})
+		}
+	}
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{{Tool: sarifTool{driver}, Results: out}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// escape workflow command data; see
+// https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+var githubData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+var githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// GitHub Actions annotations; see
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions
+func writeGithub(w io.Writer, results []Result) error {
+	for _, r := range results {
+		for _, v := range r.Violations {
+			command := "warning"
+			if v.Level >= config.RuleError {
+				command = "error"
+			}
+			_, err := fmt.Fprintf(w, "::%s title=%s::%s\n",
+				command,
+				githubProperty.Replace(v.Rule),
+				githubData.Replace(fmt.Sprintf("%s: %s", r.name(), v.Message)),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
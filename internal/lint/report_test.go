@@ -0,0 +1,55 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/config"
+)
+
+func TestWriteReport(t *testing.T) {
+	results := []Result{
+		{Commit: "0123456789abcdef", Header: "feat: ok"},
+		{Commit: "fedcba9876543210", Header: "Merge branch 'x'", Skipped: "merge commit"},
+		{
+			Commit: "abcdef0123456789", Header: "bad, 100%",
+			Violations: []Violation{
+				{Rule: "type-empty", Level: config.RuleError, Message: "type must not be empty"},
+				{Rule: "body-leading-blank", Level: config.RuleWarn, Message: "body must have\na leading blank line"},
+			},
+		},
+	}
+	test := func(format string, expected ...string) func(*testing.T) {
+		return func(t *testing.T) {
+			out := strings.Builder{}
+			if err := WriteReport(&out, format, results); err != nil {
+				fmt.Printf("%+v\n", err)
+				t.FailNow()
+			}
+			for _, e := range expected {
+				if !strings.Contains(out.String(), e) {
+					fmt.Printf("expected %q in:\n%s\n", e, out.String())
+					t.Fail()
+				}
+			}
+		}
+	}
+	t.Run("text", test("text",
+		"0123456 feat: ok (ok)\n",
+		"fedcba9 Merge branch 'x' (skipped: merge commit)\n",
+		"abcdef0 bad, 100% (2 problem(s))\n  error: type must not be empty [type-empty]\n",
+	))
+	t.Run("json", test("json", `"skipped": "merge commit"`, `"level": "warn"`))
+	t.Run("junit", test("junit", `tests="3" failures="1" skipped="1"`, `<skipped message="merge commit">`))
+	t.Run("sarif", test("sarif", `"version": "2.1.0"`, `"ruleId": "type-empty"`, `"level": "warning"`))
+	t.Run("github", test("github",
+		"::error title=type-empty::abcdef0 bad, 100%25: type must not be empty\n",
+		"::warning title=body-leading-blank::abcdef0 bad, 100%25: body must have%0Aa leading blank line\n",
+	))
+	t.Run("unknown formats are rejected", func(t *testing.T) {
+		if err := WriteReport(&strings.Builder{}, "xml", results); err == nil {
+			t.Fail()
+		}
+	})
+}
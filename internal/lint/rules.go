@@ -0,0 +1,236 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/skalt/git-cc/internal/config"
+)
+
+// turn a rule's value into a list of strings, e.g. for enums or cases.
+func toStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			result = append(result, fmt.Sprintf("%v", item))
+		}
+		return result
+	case *config.OrderedMap:
+		keys, _ := config.ZippedOrderedKeyValuePairs(v)
+		return keys
+	default:
+		return nil
+	}
+}
+
+func emptyCheck(part string, get func(*commit) string) check {
+	return func(c *commit, _ interface{}, never bool) (bool, string) {
+		empty := strings.TrimSpace(get(c)) == ""
+		return empty != never, fmt.Sprintf("%s %s be empty", part, modal(never))
+	}
+}
+
+func enumCheck(part string, get func(*commit) string) check {
+	return func(c *commit, value interface{}, never bool) (bool, string) {
+		actual := get(c)
+		if actual == "" {
+			return true, "" // left to the *-empty rules
+		}
+		allowed := toStrings(value)
+		present := slices.Contains(allowed, actual)
+		return present != never, fmt.Sprintf(
+			"%s %q %s be one of [%s]", part, actual, modal(never), strings.Join(allowed, ", "),
+		)
+	}
+}
+
+var typeEnum = enumCheck("type", func(c *commit) string { return c.cc.Type })
+var scopeEnum = enumCheck("scope", func(c *commit) string { return c.cc.Scope })
+
+// `value` maps deprecated scopes to their deprecation notices
+func scopeDeprecated(c *commit, value interface{}, never bool) (bool, string) {
+	deprecated, _ := value.(map[string]string)
+	notice, isDeprecated := deprecated[c.cc.Scope]
+	if c.cc.Scope == "" || !never {
+		return true, "" // "always" using deprecated scopes makes no sense
+	}
+	return !isDeprecated, fmt.Sprintf("scope %q is deprecated: %s", c.cc.Scope, notice)
+}
+
+var (
+	camelCase  = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	pascalCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	kebabCase  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	snakeCase  = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+)
+
+func upperFirst(s string) bool {
+	first, _ := utf8.DecodeRuneInString(s)
+	return !unicode.IsLower(first)
+}
+
+// whether `s` is written in the named case; see
+// https://commitlint.js.org/reference/rules.html#case
+func isCase(s string, name string) (bool, error) {
+	switch name {
+	case "lower-case", "lowercase":
+		return s == strings.ToLower(s), nil
+	case "upper-case", "uppercase":
+		return s == strings.ToUpper(s), nil
+	case "sentence-case", "sentencecase":
+		_, size := utf8.DecodeRuneInString(s)
+		return upperFirst(s) && s[size:] == strings.ToLower(s[size:]), nil
+	case "start-case", "startcase":
+		for _, word := range strings.Fields(s) {
+			if !upperFirst(word) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "camel-case", "camelcase":
+		return camelCase.MatchString(s), nil
+	case "pascal-case", "pascalcase":
+		return pascalCase.MatchString(s), nil
+	case "kebab-case", "kebabcase":
+		return kebabCase.MatchString(s), nil
+	case "snake-case", "snakecase":
+		return snakeCase.MatchString(s), nil
+	default:
+		return false, fmt.Errorf("unknown case %q", name)
+	}
+}
+
+func caseCheck(part string, get func(*commit) string) check {
+	return func(c *commit, value interface{}, never bool) (bool, string) {
+		actual := get(c)
+		if actual == "" {
+			return true, ""
+		}
+		cases := toStrings(value)
+		if len(cases) == 0 {
+			cases = []string{"lower-case"}
+		}
+		matched := false
+		for _, name := range cases {
+			ok, err := isCase(actual, name)
+			if err != nil {
+				return false, fmt.Sprintf("%s-case: %s", part, err)
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+		return matched != never, fmt.Sprintf(
+			"%s %q %s be %s", part, actual, modal(never), strings.Join(cases, " or "),
+		)
+	}
+}
+
+func subjectFullStop(c *commit, value interface{}, never bool) (bool, string) {
+	subject := strings.TrimSpace(c.cc.Description)
+	if subject == "" {
+		return true, ""
+	}
+	stop := "."
+	if s, ok := value.(string); ok {
+		stop = s
+	}
+	ends := strings.HasSuffix(subject, stop)
+	return ends != never, fmt.Sprintf("subject %s end with %q", modal(never), stop)
+}
+
+func headerMaxLength(c *commit, value interface{}, _ bool) (bool, string) {
+	max, ok := config.ToInt(value)
+	length := utf8.RuneCountInString(c.header)
+	if !ok || max <= 0 {
+		return true, ""
+	}
+	return length <= max, fmt.Sprintf(
+		"header must not be longer than %d characters; current length is %d", max, length,
+	)
+}
+
+func headerMinLength(c *commit, value interface{}, _ bool) (bool, string) {
+	min, _ := config.ToInt(value)
+	length := utf8.RuneCountInString(c.header)
+	return length >= min, fmt.Sprintf(
+		"header must not be shorter than %d characters; current length is %d", min, length,
+	)
+}
+
+// whether there's anything after the header
+func hasBody(c *commit) bool {
+	for _, line := range c.lines[1:] {
+		if strings.TrimSpace(line) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func bodyLeadingBlank(c *commit, _ interface{}, never bool) (bool, string) {
+	if !hasBody(c) {
+		return true, ""
+	}
+	blank := strings.TrimSpace(c.lines[1]) == ""
+	return blank != never, fmt.Sprintf("body %s have a leading blank line", modal(never))
+}
+
+func bodyMaxLineLength(c *commit, value interface{}, _ bool) (bool, string) {
+	max, ok := config.ToInt(value)
+	if !ok || max <= 0 {
+		return true, ""
+	}
+	for i, line := range strings.Split(c.cc.Body, "\n") {
+		if length := utf8.RuneCountInString(line); length > max {
+			return false, fmt.Sprintf(
+				"body's lines must not be longer than %d characters; line %d is %d characters long",
+				max, i+1, length,
+			)
+		}
+	}
+	return true, ""
+}
+
+func footerLeadingBlank(c *commit, _ interface{}, never bool) (bool, string) {
+	if len(c.cc.Footers) == 0 {
+		return true, ""
+	}
+	firstFooterLine := strings.SplitN(c.cc.Footers[0], "\n", 2)[0]
+	for i := len(c.lines) - 1; i > 0; i-- {
+		if strings.TrimSpace(c.lines[i]) == firstFooterLine {
+			blank := strings.TrimSpace(c.lines[i-1]) == ""
+			return blank != never, fmt.Sprintf("footer %s have a leading blank line", modal(never))
+		}
+	}
+	return true, ""
+}
+
+// `value` lists the required footers the commit lacks
+func footerRequired(c *commit, value interface{}, _ bool) (bool, string) {
+	missing, _ := value.([]config.RequiredFooter)
+	if len(missing) == 0 {
+		return true, ""
+	}
+	tokens := make([]string, 0, len(missing))
+	for _, footer := range missing {
+		required := footer.Token
+		if footer.Pattern != nil {
+			required += fmt.Sprintf(" matching /%s/", footer.Pattern)
+		}
+		tokens = append(tokens, required)
+	}
+	return false, fmt.Sprintf(
+		"%s commits must have footers: %s", c.cc.Type, strings.Join(tokens, ", "),
+	)
+}
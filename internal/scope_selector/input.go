@@ -9,6 +9,7 @@ import (
 	"github.com/atotto/clipboard"
 	"github.com/skalt/git-cc/internal/config"
 	"github.com/skalt/git-cc/internal/helpbar"
+	"github.com/skalt/git-cc/internal/i18n"
 	"github.com/skalt/git-cc/internal/single_select"
 	"github.com/skalt/git-cc/internal/utils"
 	"github.com/skalt/git-cc/pkg/parser"
@@ -21,52 +22,67 @@ type Model struct {
 	helpBar           helpbar.Model
 	newScope          string
 	copiedToClipboard bool
+	// deprecated scopes are valid, but aren't offered as options
+	deprecated map[string]string
 }
 
 type editorStartMsg struct{}
 type editorFinishedMsg struct{ err error }
 
-// the method for determining if the current input matches an option.
-func match(m *single_select.Model, query string, option string) bool {
-	if option == "new scope" {
-		for _, opt := range m.Options {
-			if query == opt {
-				return false
+// the method for determining if the current input matches an option or one of
+// its aliases.
+func matcher(aliases map[string][]string) func(*single_select.Model, string, string) bool {
+	matchStart := single_select.MatchStartOrAlias(aliases)
+	return func(m *single_select.Model, query string, option string) bool {
+		if option == "new scope" {
+			for _, opt := range m.Options {
+				if query == opt {
+					return false
+				}
 			}
+			return true
+		} else {
+			return matchStart(m, query, option)
 		}
-		return true
-	} else {
-		return single_select.MatchStart(m, query, option)
 	}
 }
 
+func scopeAliases(cfg *config.Cfg) map[string][]string {
+	aliases := make(map[string][]string, len(cfg.ScopeInfo))
+	for name, info := range cfg.ScopeInfo {
+		aliases[name] = info.Aliases
+	}
+	return aliases
+}
+
 // given options from config, add the leading "unscoped" and trailing "new scope" options
 func makeOptions(options *config.OrderedMap) (keys []string, values []string) {
 	keys, values = config.ZippedOrderedKeyValuePairs(options)
 	keys = append(append([]string{""}, keys...), "new scope")
-	values = append(append([]string{"unscoped; affects the entire project"}, values...), "edit a new scope into your configuration file")
+	values = append(append([]string{i18n.T("scope.unscoped")}, values...), i18n.T("scope.new"))
 	return keys, values
 }
 
 func NewModel(cc *parser.CC, cfg config.Cfg) Model {
-	options, hints := makeOptions(cfg.Scopes)
+	options, hints := makeOptions(cfg.ActiveScopes())
 	newScope := ""
 	copiedToClipboard := false
 	return Model{
 		single_select.NewModel(
-			config.Faint("select a scope:"),
+			config.Faint(i18n.T("prompt.scope")),
 			cc.Scope,
 			options, hints,
-			match,
+			matcher(scopeAliases(&cfg)),
 		),
 		helpbar.NewModel(
-			config.HelpSubmit,
-			config.HelpSelect,
-			config.HelpBack,
-			config.HelpCancel,
+			i18n.T("help.submit"),
+			i18n.T("help.select"),
+			i18n.T("help.back"),
+			i18n.T("help.cancel"),
 		),
 		newScope,
 		copiedToClipboard,
+		cfg.DeprecatedScopes(),
 	}
 }
 
@@ -76,13 +92,12 @@ func (m Model) Value() string {
 
 func (m Model) Render(s io.StringWriter) {
 	if m.newScope != "" {
-		_ = utils.Must(s.WriteString("new scope \""))
-		_ = utils.Must(s.WriteString(m.newScope))
-		_ = utils.Must(s.WriteString("\" "))
+		message := i18n.T("scope.copied")
 		if !m.copiedToClipboard {
-			_ = utils.Must(s.WriteString("not "))
+			message = i18n.T("scope.not_copied")
 		}
-		_ = utils.Must(s.WriteString("copied to clipboard\n"))
+		_ = utils.Must(s.WriteString(fmt.Sprintf(message, m.newScope)))
+		_ = utils.Must(s.WriteString("\n"))
 	}
 	m.input.Render(s)
 	_ = utils.Must(s.WriteString("\n"))
@@ -145,7 +160,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			})
 			return m, cmd
 		} // else {} // TODO: warn about parse error
-		values, hints := makeOptions(config.CentralStore.Scopes)
+		values, hints := makeOptions(config.CentralStore.ActiveScopes())
+		m.deprecated = config.CentralStore.DeprecatedScopes()
 		m.input.Options = values
 		m.input.Hints = hints
 		if m.input.Cursor >= len(m.input.Options) {
@@ -159,6 +175,9 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 func (m Model) ShouldSkip(currentValue string) bool {
+	if _, deprecated := m.deprecated[currentValue]; deprecated {
+		return true // already warned about by the scope-deprecated rule
+	}
 	for _, opt := range m.input.Options {
 		if currentValue == opt && opt != "" {
 			return true
@@ -0,0 +1,31 @@
+package scope_selector
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/pkg/parser"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestNewModel(t *testing.T) {
+	scopes := orderedmap.New[string, string]()
+	scopes.Set("cmd", "the CLI")
+	scopes.Set("legacy", "the old CLI")
+	scopes.Set("parser", "parses commits")
+	cfg := config.Cfg{
+		Scopes:    scopes,
+		ScopeInfo: map[string]config.ScopeInfo{"legacy": {Deprecated: "use cmd instead"}},
+	}
+	m := NewModel(&parser.CC{}, cfg)
+	if expected := []string{"", "cmd", "parser", "new scope"}; !slices.Equal(m.input.Options, expected) {
+		fmt.Printf("expected the options %q, got %q\n", expected, m.input.Options)
+		t.Fail()
+	}
+	if m.deprecated["legacy"] != "use cmd instead" {
+		fmt.Printf("expected legacy to be known as deprecated, got %+v\n", m.deprecated)
+		t.Fail()
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/muesli/reflow/padding"
 	"github.com/muesli/reflow/wordwrap"
 	term "github.com/muesli/termenv"
+	"github.com/skalt/git-cc/internal/i18n"
 	"github.com/skalt/git-cc/internal/utils"
 )
 
@@ -40,7 +41,7 @@ func NewModel(context string, value string, options []string, hints []string, ma
 		panic(fmt.Errorf("len(hints) %d != %d len(options)", len(hints), len(options)))
 	}
 	input := textinput.New()
-	input.Placeholder = "type to select"
+	input.Placeholder = i18n.T("placeholder.select")
 	input.Prompt = "   "
 	input.SetValue(value)
 	input.SetCursor(len(value))
@@ -97,6 +98,21 @@ func MatchStart(m *Model, query string, option string) bool {
 	return len(query) <= len(option) && option[0:len(query)] == query
 }
 
+// match options by the start of their names or any of their aliases
+func MatchStartOrAlias(aliases map[string][]string) func(*Model, string, string) bool {
+	return func(m *Model, query string, option string) bool {
+		if MatchStart(m, query, option) {
+			return true
+		}
+		for _, alias := range aliases[option] {
+			if MatchStart(m, query, alias) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 func (m Model) filter(startingWith string) ([][2]string, [][2]string) {
 	matched, filtered := [][2]string{}, [][2]string{}
 	for i, opt := range m.Options {
@@ -0,0 +1,205 @@
+package staged_panel
+
+// a scrollable list of the staged changes and how many lines each adds and
+// deletes, like `git diff --cached --stat`
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/muesli/reflow/ansi"
+	"github.com/skalt/git-cc/internal/config"
+	"github.com/skalt/git-cc/internal/git"
+	"github.com/skalt/git-cc/internal/helpbar"
+	"github.com/skalt/git-cc/internal/i18n"
+	"github.com/skalt/git-cc/internal/utils"
+)
+
+const (
+	// how many files are listed at once until the terminal's height is known
+	defaultHeight = 8
+	// the widest the +/- graph of a file's changes gets, as with `git diff --stat`
+	maxGraphWidth = 40
+)
+
+type Model struct {
+	files []git.FileStat
+	// why the staged changes couldn't be listed, if they couldn't
+	err error
+	// the index of the highlighted file
+	cursor int
+	// the index of the first file shown
+	offset int
+	// how many files are listed at once
+	height int
+	width  int
+	// shows a file's changes, e.g. in a pager
+	showDiff func(git.FileStat) tea.Cmd
+	helpBar  helpbar.Model
+}
+
+func NewModel(files []git.FileStat, err error, showDiff func(git.FileStat) tea.Cmd) Model {
+	return Model{
+		files:    files,
+		err:      err,
+		height:   defaultHeight,
+		showDiff: showDiff,
+		helpBar: helpbar.NewModel(
+			i18n.T("help.scroll"), i18n.T("help.diff"), i18n.T("help.hide"),
+		),
+	}
+}
+
+// the highlighted file, if there are any
+func (m Model) Selected() (git.FileStat, bool) {
+	if m.cursor >= len(m.files) {
+		return git.FileStat{}, false
+	}
+	return m.files[m.cursor], true
+}
+
+// whether the panel responds to a key, rather than the prompt it's shown with.
+// Its help bar says which keys it takes from the prompt.
+func (m Model) Captures(msg tea.KeyPressMsg) bool {
+	switch msg.Code {
+	case tea.KeyUp, tea.KeyDown, tea.KeyPgUp, tea.KeyPgDown, tea.KeyHome, tea.KeyEnd:
+		return true
+	case tea.KeyEnter:
+		return msg.Mod == 0
+	}
+	return false
+}
+
+// move the cursor, keeping it within the files and on screen
+func (m Model) moveTo(cursor int) Model {
+	m.cursor = max(0, min(cursor, len(m.files)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+	return m
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		// leave most of the screen to the prompt
+		m.height = max(3, msg.Height/3)
+		m = m.moveTo(m.cursor)
+		m.helpBar, cmd = m.helpBar.Update(msg)
+	case tea.KeyPressMsg:
+		switch msg.Code {
+		case tea.KeyUp:
+			m = m.moveTo(m.cursor - 1)
+		case tea.KeyDown:
+			m = m.moveTo(m.cursor + 1)
+		case tea.KeyPgUp:
+			m = m.moveTo(m.cursor - m.height)
+		case tea.KeyPgDown:
+			m = m.moveTo(m.cursor + m.height)
+		case tea.KeyHome:
+			m = m.moveTo(0)
+		case tea.KeyEnd:
+			m = m.moveTo(len(m.files) - 1)
+		case tea.KeyEnter:
+			if file, ok := m.Selected(); ok && m.showDiff != nil {
+				cmd = m.showDiff(file)
+			}
+		}
+	}
+	return m, cmd
+}
+
+// the name of a file as `git diff --stat` shows it, e.g. `old.go => new.go`
+func name(file git.FileStat) string {
+	if file.From != "" {
+		return file.From + " => " + file.Path
+	}
+	return file.Path
+}
+
+func (m Model) Render(s io.StringWriter) {
+	if m.err != nil {
+		_ = utils.Must(s.WriteString(config.Faint(m.err.Error())))
+		_ = utils.Must(s.WriteString("\n"))
+		return
+	}
+	if len(m.files) == 0 {
+		_ = utils.Must(s.WriteString(config.Faint(i18n.T("staged.empty"))))
+		_ = utils.Must(s.WriteString("\n"))
+		return
+	}
+	added, deleted, most, nameWidth, countWidth := 0, 0, 0, 0, 1
+	for _, file := range m.files {
+		added += file.Added
+		deleted += file.Deleted
+		most = max(most, file.Added+file.Deleted)
+		nameWidth = max(nameWidth, ansi.PrintableRuneWidth(name(file)))
+		countWidth = max(countWidth, len(strconv.Itoa(file.Added+file.Deleted)))
+	}
+	graphWidth := maxGraphWidth
+	if m.width > 0 {
+		// leave room for the cursor, the separator, and the count
+		nameWidth = min(nameWidth, max(10, m.width/2))
+		graphWidth = max(0, min(graphWidth, m.width-nameWidth-countWidth-6))
+	}
+
+	summary := fmt.Sprintf(i18n.T("staged.summary"), len(m.files), added, deleted)
+	if len(m.files) > m.height {
+		end := min(m.offset+m.height, len(m.files))
+		summary += fmt.Sprintf(" [%d-%d/%d]", m.offset+1, end, len(m.files))
+	}
+	_ = utils.Must(s.WriteString(config.Faint(summary)))
+	_ = utils.Must(s.WriteString("\n"))
+	for i := m.offset; i < len(m.files) && i < m.offset+m.height; i++ {
+		file := m.files[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		line := cursor + pad(truncate(name(file), nameWidth), nameWidth) + " | "
+		if file.Binary {
+			line += "Bin"
+		} else {
+			total := file.Added + file.Deleted
+			plus, minus := file.Added, file.Deleted
+			if most > graphWidth {
+				// scale the graph like git does, keeping any change visible
+				plus, minus = scale(file.Added, most, graphWidth), scale(file.Deleted, most, graphWidth)
+			}
+			line += fmt.Sprintf("%*d %s%s", countWidth, total, strings.Repeat("+", plus), strings.Repeat("-", minus))
+		}
+		_ = utils.Must(s.WriteString(line))
+		_ = utils.Must(s.WriteString("\n"))
+	}
+	m.helpBar.Render(s)
+	_ = utils.Must(s.WriteString("\n"))
+}
+
+// scale a count of lines to the width of the graph, showing at least one
+// character for any change
+func scale(n int, most int, width int) int {
+	if n == 0 || most == 0 {
+		return 0
+	}
+	return max(1, n*width/most)
+}
+
+// shorten a path from the left, since the end of a path says the most about it
+func truncate(path string, width int) string {
+	runes := []rune(path)
+	if len(runes) <= width {
+		return path
+	}
+	return "..." + string(runes[len(runes)-max(0, width-3):])
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-ansi.PrintableRuneWidth(s)))
+}
@@ -4,8 +4,10 @@ import (
 	"io"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/muesli/reflow/wordwrap"
 	"github.com/skalt/git-cc/internal/config"
 	"github.com/skalt/git-cc/internal/helpbar"
+	"github.com/skalt/git-cc/internal/i18n"
 	"github.com/skalt/git-cc/internal/single_select"
 	"github.com/skalt/git-cc/internal/utils"
 	"github.com/skalt/git-cc/pkg/parser"
@@ -14,20 +16,32 @@ import (
 type Model struct {
 	input   single_select.Model
 	helpBar helpbar.Model
+	// longer explanations of each commit type, if configured
+	help  map[string]string
+	width int
 }
 
 func NewModel(cc *parser.CC, cfg *config.Cfg) Model {
 	types, hints := config.ZippedOrderedKeyValuePairs(cfg.CommitTypes)
+	help := make(map[string]string, len(types))
+	aliases := make(map[string][]string, len(types))
+	for _, t := range types {
+		info := cfg.TypeInfoOf(t)
+		help[t] = info.Help
+		aliases[t] = info.Aliases
+	}
 	return Model{
 		single_select.NewModel(
-			config.Faint("select a commit type: "),
+			config.Faint(i18n.T("prompt.type")),
 			cc.Type,
 			types, hints,
-			single_select.MatchStart,
+			single_select.MatchStartOrAlias(aliases),
 		),
 		helpbar.NewModel(
-			config.HelpSubmit, config.HelpSelect, config.HelpCancel,
+			i18n.T("help.submit"), i18n.T("help.select"), i18n.T("help.cancel"),
 		),
+		help,
+		0,
 	}
 }
 
@@ -37,12 +51,20 @@ func (m Model) Value() string {
 
 func (m Model) Render(s io.StringWriter) {
 	m.input.Render(s)
+	if help := m.help[m.Value()]; help != "" {
+		_ = utils.Must(s.WriteString("\n"))
+		_ = utils.Must(s.WriteString(config.Faint(wordwrap.String(help, m.width))))
+		_ = utils.Must(s.WriteString("\n"))
+	}
 	_ = utils.Must(s.WriteString("\n"))
 	m.helpBar.Render(s)
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = msg.Width
+	}
 	m.helpBar, _ = m.helpBar.Update(msg)
 	m.input, cmd = m.input.Update(msg)
 	return m, cmd
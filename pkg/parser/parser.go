@@ -143,3 +143,17 @@ func ParseAsMuchOfCCAsPossible(fullCommit string) (*CC, error) {
 	}
 	return result, err
 }
+
+// Split a footer like `Refs: 676104e` or `Refs #133` into its token and value.
+// A `#` separator is kept as part of the value, e.g. `#133`.
+func SplitFooter(footer string) (token string, value string) {
+	result, err := FooterToken([]rune(footer))
+	if err != nil {
+		return "", trimWhitespace(footer)
+	}
+	value = trimWhitespace(string(result.Remaining))
+	if strings.HasSuffix(result.Value, "#") {
+		value = "#" + value
+	}
+	return strings.TrimRight(result.Value, ": #"), value
+}
@@ -284,3 +284,23 @@ func TestParsingPartialCommit(t *testing.T) {
 
 	t.Run("invalid `type\nbody`", test("feat\nbody", CC{Type: "feat", Body: "\nbody"}))
 }
+
+func TestSplitFooter(t *testing.T) {
+	test := func(footer string, token string, value string) func(*testing.T) {
+		return func(t *testing.T) {
+			actualToken, actualValue := SplitFooter(footer)
+			if actualToken != token {
+				fmt.Printf("token: expected: %q actual: %q\n", token, actualToken)
+				t.Fail()
+			}
+			if actualValue != value {
+				fmt.Printf("value: expected: %q actual: %q\n", value, actualValue)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("colon-separated", test("Refs: 676104e, a215868", "Refs", "676104e, a215868"))
+	t.Run("hash-separated", test("Refs #133", "Refs", "#133"))
+	t.Run("breaking changes", test("BREAKING CHANGE: drop node 6", "BREAKING CHANGE", "drop node 6"))
+	t.Run("not a footer", test("just some text", "", "just some text"))
+}
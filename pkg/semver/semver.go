@@ -0,0 +1,151 @@
+package semver
+
+// parsing, comparing, and bumping semantic versions; see https://semver.org
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Version struct {
+	Major, Minor, Patch int
+	// dot-separated pre-release identifiers, e.g. ["rc", "1"] for `1.0.0-rc.1`
+	Pre []string
+	// build metadata, e.g. "20060102" for `1.0.0+20060102`; ignored when
+	// comparing versions
+	Build string
+}
+
+// see https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+var pattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// parse a version like `1.2.3-rc.1+build`, without any prefix like `v`
+func Parse(s string) (v Version, err error) {
+	match := pattern.FindStringSubmatch(s)
+	if match == nil {
+		return v, fmt.Errorf("not a semantic version: %q", s)
+	}
+	for i, dest := range [...]*int{&v.Major, &v.Minor, &v.Patch} {
+		if *dest, err = strconv.Atoi(match[i+1]); err != nil {
+			return v, fmt.Errorf("%q: %w", s, err)
+		}
+	}
+	if match[4] != "" {
+		v.Pre = strings.Split(match[4], ".")
+	}
+	v.Build = match[5]
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+func (v Version) IsPrerelease() bool {
+	return len(v.Pre) > 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compare two pre-release identifiers: numeric identifiers sort numerically
+// and before alphanumeric ones, which sort lexically.
+func compareIdentifiers(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// returns -1, 0, or 1 if a has lower, equal, or higher precedence than b; see
+// https://semver.org/#spec-item-11
+func Compare(a, b Version) int {
+	for _, pair := range [...][2]int{{a.Major, b.Major}, {a.Minor, b.Minor}, {a.Patch, b.Patch}} {
+		if c := compareInts(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a.Pre) == 0 && len(b.Pre) == 0:
+		return 0
+	case len(a.Pre) == 0:
+		return 1 // a release outranks its pre-releases
+	case len(b.Pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.Pre) && i < len(b.Pre); i++ {
+		if c := compareIdentifiers(a.Pre[i], b.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a.Pre), len(b.Pre))
+}
+
+// how much a change moves the version
+type Level int
+
+const (
+	None Level = iota
+	Patch
+	Minor
+	Major
+)
+
+func (l Level) String() string {
+	return [...]string{"none", "patch", "minor", "major"}[l]
+}
+
+func ParseLevel(s string) (Level, error) {
+	for l := None; l <= Major; l++ {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return None, fmt.Errorf("unknown version bump %q; expected one of major, minor, patch, or none", s)
+}
+
+// the next release after v for a change of the given level. Before 1.0.0,
+// anything goes (https://semver.org/#spec-item-4), so breaking changes only
+// bump the minor version and features only the patch version.
+func (v Version) Bump(level Level) Version {
+	if v.Major == 0 && level > Patch {
+		level--
+	}
+	next := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch level {
+	case Major:
+		next = Version{Major: v.Major + 1}
+	case Minor:
+		next = Version{Major: v.Major, Minor: v.Minor + 1}
+	case Patch:
+		next.Patch++
+	}
+	return next
+}
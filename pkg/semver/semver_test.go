@@ -0,0 +1,80 @@
+package semver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	test := func(input string, ok bool) func(*testing.T) {
+		return func(t *testing.T) {
+			v, err := Parse(input)
+			if (err == nil) != ok {
+				fmt.Printf("%q: unexpected error %v\n", input, err)
+				t.FailNow()
+			}
+			if ok && v.String() != input {
+				fmt.Printf("expected %q, got %q\n", input, v.String())
+				t.Fail()
+			}
+		}
+	}
+	t.Run("releases", test("1.2.3", true))
+	t.Run("pre-releases", test("1.2.3-rc.1", true))
+	t.Run("build metadata", test("1.2.3-alpha.beta+exp.sha.5114f85", true))
+	t.Run("prefixes", test("v1.2.3", false))
+	t.Run("leading zeroes", test("01.2.3", false))
+	t.Run("partial versions", test("1.2", false))
+}
+
+func TestCompare(t *testing.T) {
+	// the example from https://semver.org/#spec-item-11
+	expected := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	shuffled := []string{}
+	for i := range expected {
+		shuffled = append(shuffled, expected[(i*7)%len(expected)])
+	}
+	versions := []Version{}
+	for _, s := range shuffled {
+		v, err := Parse(s)
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			t.FailNow()
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return Compare(versions[i], versions[j]) < 0 })
+	actual := []string{}
+	for _, v := range versions {
+		actual = append(actual, v.String())
+	}
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		fmt.Printf("expected %v\n     got %v\n", expected, actual)
+		t.Fail()
+	}
+}
+
+func TestBump(t *testing.T) {
+	test := func(from string, level Level, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			v, _ := Parse(from)
+			if actual := v.Bump(level).String(); actual != expected {
+				fmt.Printf("expected %s, got %s\n", expected, actual)
+				t.Fail()
+			}
+		}
+	}
+	t.Run("major", test("1.2.3", Major, "2.0.0"))
+	t.Run("minor", test("1.2.3", Minor, "1.3.0"))
+	t.Run("patch", test("1.2.3", Patch, "1.2.4"))
+	t.Run("none", test("1.2.3", None, "1.2.3"))
+	t.Run("pre-releases are dropped", test("1.2.3-rc.1+build", Patch, "1.2.4"))
+	t.Run("breaking changes before 1.0.0", test("0.2.3", Major, "0.3.0"))
+	t.Run("features before 1.0.0", test("0.2.3", Minor, "0.2.4"))
+	t.Run("fixes before 1.0.0", test("0.2.3", Patch, "0.2.4"))
+}